@@ -37,6 +37,8 @@ func Analyzer(input string) string {
 			result, err = commands.ParserFDisk(tokens[1:])
 		case "mount":
 			result, err = commands.ParserMount(tokens[1:])
+		case "unmount":
+			result, err = commands.ParserUnmount(tokens[1:])
 		case "mkfs":
 			result, err = commands.ParserMkFs(tokens[1:])
 		case "rep":
@@ -61,6 +63,32 @@ func Analyzer(input string) string {
 			result, err = commands.ParserMkFile(tokens[1:])
 		case "cat":
 			result, err = commands.ParserCat(tokens[1:])
+		case "edit":
+			result, err = commands.ParserEdit(tokens[1:])
+		case "rename":
+			result, err = commands.ParserRename(tokens[1:])
+		case "copy":
+			result, err = commands.ParserCopy(tokens[1:])
+		case "move":
+			result, err = commands.ParserMove(tokens[1:])
+		case "find":
+			result, err = commands.ParserFind(tokens[1:])
+		case "chpass":
+			result, err = commands.ParserChPass(tokens[1:])
+		case "umask":
+			result, err = commands.ParserUmask(tokens[1:])
+		case "chmod":
+			result, err = commands.ParserChmod(tokens[1:])
+		case "chown":
+			result, err = commands.ParserChown(tokens[1:])
+		case "remove":
+			result, err = commands.ParserRemove(tokens[1:])
+		case "journaling":
+			result, err = commands.ParserJournaling(tokens[1:])
+		case "loss":
+			result, err = commands.ParserLoss(tokens[1:])
+		case "recovery":
+			result, err = commands.ParserRecovery(tokens[1:])
 		default:
 			err = fmt.Errorf("Error: command not found: %s", tokens[0])
 		}
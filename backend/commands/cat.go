@@ -54,7 +54,14 @@ func (cmd *Cat) commandCat() (string, error) {
 	}
 
 	sb := &structures.SuperBlock{}
-	if err := sb.ReadSuperBlock(partitionPath, int64(mountedPartition.PartStart)); err != nil {
+	if err := sb.ReadSuperBlock(partitionPath, int64(mountedPartition.Start)); err != nil {
+		return "", err
+	}
+
+	global.ParserUserData(sb.GetFile(partitionPath, 0, []string{"users.txt"}))
+
+	uid, gid, err := global.GetLoggedUserIDs()
+	if err != nil {
 		return "", err
 	}
 
@@ -68,6 +75,10 @@ func (cmd *Cat) commandCat() (string, error) {
 				result = append(result, part)
 			}
 		}
+		if _, err := sb.CheckAccess(partitionPath, result, uid, gid, structures.PermissionRead); err != nil {
+			return "", err
+		}
+
 		response := sb.GetFile(partitionPath, 0, result)
 		if response != "" {
 			sb2.WriteString(response)
@@ -68,7 +68,11 @@ func (cmd *ChGRP) commandChGRP() error {
 	}
 
 	sb := &structures.SuperBlock{}
-	if err := sb.ReadSuperBlock(partitionPath, int64(mountedPartition.PartStart)); err != nil {
+	if err := sb.ReadSuperBlock(partitionPath, int64(mountedPartition.Start)); err != nil {
+		return err
+	}
+
+	if err := sb.CheckJournalSpace(partitionPath, "/users.txt", cmd.User+","+cmd.GRP); err != nil {
 		return err
 	}
 
@@ -86,7 +90,11 @@ func (cmd *ChGRP) commandChGRP() error {
 		return err
 	}
 
-	if err := sb.WriteSuperBlock(partitionPath, int64(mountedPartition.PartStart), int64(mountedPartition.PartStart+int32(binary.Size(sb)))); err != nil {
+	if err := sb.WriteSuperBlock(partitionPath, int64(mountedPartition.Start), int64(mountedPartition.Start+int32(binary.Size(sb)))); err != nil {
+		return err
+	}
+
+	if err := sb.AddJournal(partitionPath, "chgrp", "/users.txt", cmd.User+","+cmd.GRP); err != nil {
 		return err
 	}
 
@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"backend/global"
+	"backend/structures"
+	"backend/utils"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type Chmod struct {
+	Path string
+	Ugo  string
+	R    bool
+}
+
+func ParserChmod(tokens []string) (string, error) {
+	cmd := &Chmod{}
+
+	args := strings.Join(tokens, " ")
+	re := regexp.MustCompile(`(?i)-path(?-i)="[^"]+"|(?i)-path(?-i)=\S+|(?i)-ugo(?-i)=\S+|(?i)-r\b`)
+	matches := re.FindAllString(args, -1)
+
+	for _, match := range matches {
+		if strings.ToLower(match) == "-r" {
+			cmd.R = true
+			continue
+		}
+
+		key, value, err := utils.ParseToken(match)
+		if err != nil {
+			return "", err
+		}
+
+		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
+			value = strings.Trim(value, "\"")
+		}
+
+		switch key {
+		case "-path":
+			if value == "" {
+				return "", fmt.Errorf("invalid path: %s", value)
+			}
+			cmd.Path = value
+		case "-ugo":
+			if _, err := structures.ParsePermission(value); err != nil {
+				return "", err
+			}
+			cmd.Ugo = value
+		}
+	}
+
+	if cmd.Path == "" {
+		return "", fmt.Errorf("path is required")
+	}
+
+	if cmd.Ugo == "" {
+		return "", fmt.Errorf("ugo is required")
+	}
+
+	if err := cmd.commandChmod(); err != nil {
+		return "", err
+	}
+
+	return cmd.Print(), nil
+}
+
+func (cmd *Chmod) commandChmod() error {
+	if user, _, err := global.GetLoggedUser(); user != "root" || err != nil {
+		return fmt.Errorf("permission denied")
+	}
+
+	mountedPartition, partitionPath, err := global.GetMountedPartition(global.LoggedPartition)
+	if err != nil {
+		return err
+	}
+
+	sb := &structures.SuperBlock{}
+	if err := sb.ReadSuperBlock(partitionPath, int64(mountedPartition.Start)); err != nil {
+		return err
+	}
+
+	recursive := ""
+	if cmd.R {
+		recursive = "r"
+	}
+	content := cmd.Ugo + "," + recursive
+
+	if err := sb.CheckJournalSpace(partitionPath, cmd.Path, content); err != nil {
+		return err
+	}
+
+	if err := chmodPath(sb, partitionPath, cmd.Path, cmd.Ugo, cmd.R); err != nil {
+		return err
+	}
+
+	if err := sb.WriteSuperBlock(partitionPath, int64(mountedPartition.Start), int64(mountedPartition.Start+int32(binary.Size(sb)))); err != nil {
+		return err
+	}
+
+	if err := sb.AddJournal(partitionPath, "chmod", cmd.Path, content); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func chmodPath(sb *structures.SuperBlock, partitionPath, path, ugo string, recursive bool) error {
+	perm, err := structures.ParsePermission(ugo)
+	if err != nil {
+		return err
+	}
+
+	index := sb.GetInodeIndex(partitionPath, splitPath(path))
+	if index == -1 {
+		return fmt.Errorf("path not found: %s", path)
+	}
+
+	return sb.UpdateInodes(partitionPath, index, recursive, func(inode *structures.Inode) bool {
+		inode.IPerm = perm
+		return true
+	})
+}
+
+func (cmd *Chmod) Print() string {
+	if cmd.R {
+		return fmt.Sprintf("permissions of %s and its contents changed to %s", cmd.Path, cmd.Ugo)
+	}
+
+	return fmt.Sprintf("permissions of %s changed to %s", cmd.Path, cmd.Ugo)
+}
@@ -0,0 +1,166 @@
+package commands
+
+import (
+	"backend/global"
+	"backend/structures"
+	"backend/utils"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type Chown struct {
+	Path    string
+	Usuario string
+	R       bool
+	skipped int
+}
+
+func ParserChown(tokens []string) (string, error) {
+	cmd := &Chown{}
+
+	args := strings.Join(tokens, " ")
+	re := regexp.MustCompile(`(?i)-path(?-i)="[^"]+"|(?i)-path(?-i)=\S+|(?i)-usuario(?-i)="[^"]+"|(?i)-usuario(?-i)=\S+|(?i)-r\b`)
+	matches := re.FindAllString(args, -1)
+
+	for _, match := range matches {
+		if strings.ToLower(match) == "-r" {
+			cmd.R = true
+			continue
+		}
+
+		key, value, err := utils.ParseToken(match)
+		if err != nil {
+			return "", err
+		}
+
+		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
+			value = strings.Trim(value, "\"")
+		}
+
+		switch key {
+		case "-path":
+			if value == "" {
+				return "", fmt.Errorf("invalid path: %s", value)
+			}
+			cmd.Path = value
+		case "-usuario":
+			if value == "" {
+				return "", fmt.Errorf("invalid user: %s", value)
+			}
+			cmd.Usuario = value
+		}
+	}
+
+	if cmd.Path == "" {
+		return "", fmt.Errorf("path is required")
+	}
+
+	if cmd.Usuario == "" {
+		return "", fmt.Errorf("usuario is required")
+	}
+
+	if err := cmd.commandChown(); err != nil {
+		return "", err
+	}
+
+	return cmd.Print(), nil
+}
+
+func (cmd *Chown) commandChown() error {
+	if !global.IsUserLogged() {
+		return fmt.Errorf("you must be logged in")
+	}
+
+	mountedPartition, partitionPath, err := global.GetMountedPartition(global.LoggedPartition)
+	if err != nil {
+		return err
+	}
+
+	sb := &structures.SuperBlock{}
+	if err := sb.ReadSuperBlock(partitionPath, int64(mountedPartition.Start)); err != nil {
+		return err
+	}
+
+	global.ParserUserData(sb.GetFile(partitionPath, 0, []string{"users.txt"}))
+
+	uid, gid, err := global.GetLoggedUserIDs()
+	if err != nil {
+		return err
+	}
+
+	if _, err := sb.CheckAccess(partitionPath, splitPath(cmd.Path), uid, gid, 0); err != nil {
+		return err
+	}
+
+	// The caller is journaled too, recursive changes made by other users than root skip entries
+	recursive := ""
+	if cmd.R {
+		recursive = "r"
+	}
+	content := fmt.Sprintf("%s,%s,%d", cmd.Usuario, recursive, uid)
+
+	if err := sb.CheckJournalSpace(partitionPath, cmd.Path, content); err != nil {
+		return err
+	}
+
+	skipped, err := chownPath(sb, partitionPath, cmd.Path, cmd.Usuario, cmd.R, uid)
+	if err != nil {
+		return err
+	}
+	cmd.skipped = skipped
+
+	if err := sb.WriteSuperBlock(partitionPath, int64(mountedPartition.Start), int64(mountedPartition.Start+int32(binary.Size(sb)))); err != nil {
+		return err
+	}
+
+	if err := sb.AddJournal(partitionPath, "chown", cmd.Path, content); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// chownPath gives the inodes at path to usuario, users other than root can only change the inodes
+// they own. It returns how many inodes were left untouched for that reason
+func chownPath(sb *structures.SuperBlock, partitionPath, path, usuario string, recursive bool, uid int32) (int, error) {
+	owner, _, err := global.GetUserIDs(usuario)
+	if err != nil {
+		return 0, err
+	}
+
+	index := sb.GetInodeIndex(partitionPath, splitPath(path))
+	if index == -1 {
+		return 0, fmt.Errorf("path not found: %s", path)
+	}
+
+	inode := &structures.Inode{}
+	if err := inode.ReadInode(partitionPath, int64(sb.SInodeStart+index*sb.SInodeSize)); err != nil {
+		return 0, err
+	}
+
+	if uid != structures.RootUserID && inode.IuId != uid {
+		return 0, fmt.Errorf("permission denied: %s", path)
+	}
+
+	skipped := 0
+	err = sb.UpdateInodes(partitionPath, index, recursive, func(inode *structures.Inode) bool {
+		if uid != structures.RootUserID && inode.IuId != uid {
+			skipped++
+			return false
+		}
+		inode.IuId = owner
+		return true
+	})
+
+	return skipped, err
+}
+
+func (cmd *Chown) Print() string {
+	if cmd.skipped > 0 {
+		return fmt.Sprintf("owner of %s changed to %s, %d entries not owned by you were skipped", cmd.Path, cmd.Usuario, cmd.skipped)
+	}
+
+	return fmt.Sprintf("owner of %s changed to %s", cmd.Path, cmd.Usuario)
+}
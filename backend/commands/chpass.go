@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"backend/global"
+	"backend/structures"
+	"backend/utils"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type ChPass struct {
+	User string
+	Old  string
+	New  string
+}
+
+func ParserChPass(tokens []string) (string, error) {
+	cmd := &ChPass{}
+
+	args := strings.Join(tokens, " ")
+	re := regexp.MustCompile(`(?i)-user(?-i)="[^"]+"|(?i)-user(?-i)=\S+|(?i)-old(?-i)="[^"]+"|(?i)-old(?-i)=\S+|(?i)-new(?-i)="[^"]+"|(?i)-new(?-i)=\S+`)
+	matches := re.FindAllString(args, -1)
+
+	for _, match := range matches {
+		key, value, err := utils.ParseToken(match)
+		if err != nil {
+			return "", err
+		}
+
+		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
+			value = strings.Trim(value, "\"")
+		}
+
+		switch key {
+		case "-user":
+			if value == "" {
+				return "", fmt.Errorf("invalid user: %s", value)
+			}
+			cmd.User = value
+		case "-old":
+			cmd.Old = value
+		case "-new":
+			if value == "" {
+				return "", fmt.Errorf("invalid new password: %s", value)
+			}
+			if len(value) > 10 {
+				return "", fmt.Errorf("-new must be at most 10 characters long")
+			}
+			cmd.New = value
+		}
+	}
+
+	if cmd.New == "" {
+		return "", fmt.Errorf("new password is required")
+	}
+
+	if cmd.User == "" && cmd.Old == "" {
+		return "", fmt.Errorf("old password is required")
+	}
+
+	if err := cmd.commandChPass(); err != nil {
+		return "", err
+	}
+
+	return cmd.Print(), nil
+}
+
+func (cmd *ChPass) commandChPass() error {
+	logged, _, err := global.GetLoggedUser()
+	if err != nil {
+		return fmt.Errorf("you must be logged in")
+	}
+
+	// Only root can change the password of other users, without knowing the old one
+	if cmd.User != "" && logged != "root" {
+		return fmt.Errorf("permission denied")
+	}
+
+	if cmd.User == "" {
+		cmd.User = logged
+	}
+
+	mountedPartition, partitionPath, err := global.GetMountedPartition(global.LoggedPartition)
+	if err != nil {
+		return err
+	}
+
+	sb := &structures.SuperBlock{}
+	if err := sb.ReadSuperBlock(partitionPath, int64(mountedPartition.Start)); err != nil {
+		return err
+	}
+
+	array := []string{"users.txt"}
+
+	texto := sb.GetFile(partitionPath, 0, array)
+
+	global.ParserUserData(texto)
+
+	if cmd.Old != "" && !global.VerifyPassword(cmd.User, cmd.Old) {
+		return fmt.Errorf("invalid old password")
+	}
+
+	hash, err := global.HashPassword(cmd.New)
+	if err != nil {
+		return err
+	}
+
+	if err := sb.CheckJournalSpace(partitionPath, "/users.txt", cmd.User+","+hash); err != nil {
+		return err
+	}
+
+	if err := global.SetPassword(cmd.User, hash); err != nil {
+		return err
+	}
+
+	if _, err := sb.WriteFile(partitionPath, int32(0), array, global.ConvertToString()); err != nil {
+		return err
+	}
+
+	if err := sb.WriteSuperBlock(partitionPath, int64(mountedPartition.Start), int64(mountedPartition.Start+int32(binary.Size(sb)))); err != nil {
+		return err
+	}
+
+	if err := sb.AddJournal(partitionPath, "chpass", "/users.txt", cmd.User+","+hash); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (cmd *ChPass) Print() string {
+	return fmt.Sprintf("password of user %s changed", cmd.User)
+}
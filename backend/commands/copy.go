@@ -0,0 +1,172 @@
+package commands
+
+import (
+	"backend/global"
+	"backend/structures"
+	"backend/utils"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type Copy struct {
+	Path    string
+	Destino string
+	skipped []string
+}
+
+func ParserCopy(tokens []string) (string, error) {
+	cmd := &Copy{}
+
+	args := strings.Join(tokens, " ")
+	re := regexp.MustCompile(`(?i)-path(?-i)="[^"]+"|(?i)-path(?-i)=\S+|(?i)-destino(?-i)="[^"]+"|(?i)-destino(?-i)=\S+`)
+	matches := re.FindAllString(args, -1)
+
+	for _, match := range matches {
+		key, value, err := utils.ParseToken(match)
+		if err != nil {
+			return "", err
+		}
+
+		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
+			value = strings.Trim(value, "\"")
+		}
+
+		switch key {
+		case "-path":
+			if value == "" {
+				return "", fmt.Errorf("invalid path: %s", value)
+			}
+			cmd.Path = value
+		case "-destino":
+			if value == "" {
+				return "", fmt.Errorf("invalid destination: %s", value)
+			}
+			cmd.Destino = value
+		}
+	}
+
+	if cmd.Path == "" {
+		return "", fmt.Errorf("path is required")
+	}
+
+	if cmd.Destino == "" {
+		return "", fmt.Errorf("destino is required")
+	}
+
+	if err := cmd.commandCopy(); err != nil {
+		return "", err
+	}
+
+	return cmd.Print(), nil
+}
+
+func (cmd *Copy) commandCopy() error {
+	if !global.IsUserLogged() {
+		return fmt.Errorf("you must be logged in")
+	}
+
+	mountedPartition, partitionPath, err := global.GetMountedPartition(global.LoggedPartition)
+	if err != nil {
+		return err
+	}
+
+	sb := &structures.SuperBlock{}
+	if err := sb.ReadSuperBlock(partitionPath, int64(mountedPartition.Start)); err != nil {
+		return err
+	}
+
+	if err := sb.CheckJournalSpace(partitionPath, cmd.Path, cmd.Destino); err != nil {
+		return err
+	}
+
+	global.ParserUserData(sb.GetFile(partitionPath, 0, []string{"users.txt"}))
+
+	uid, gid, err := global.GetLoggedUserIDs()
+	if err != nil {
+		return err
+	}
+
+	skipped, err := copyPath(sb, partitionPath, splitPath(cmd.Path), splitPath(cmd.Destino), uid, gid)
+	if err != nil {
+		return err
+	}
+	cmd.skipped = skipped
+
+	if err := sb.WriteSuperBlock(partitionPath, int64(mountedPartition.Start), int64(mountedPartition.Start+int32(binary.Size(sb)))); err != nil {
+		return err
+	}
+
+	if err := sb.AddJournal(partitionPath, "copy", cmd.Path, cmd.Destino); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// copyPath copies the file or folder source inside the folder destination
+func copyPath(sb *structures.SuperBlock, partitionPath string, source, destination []string, uid, gid int32) ([]string, error) {
+	sourceName := "/" + strings.Join(source, "/")
+	if len(source) == 0 {
+		return nil, fmt.Errorf("the root folder cannot be copied")
+	}
+
+	sourceIndex, err := sb.CheckAccess(partitionPath, source, uid, gid, structures.PermissionRead)
+	if err != nil {
+		return nil, err
+	}
+
+	destinationIndex, err := sb.CheckAccess(partitionPath, destination, uid, gid, structures.PermissionWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	folder := &structures.Inode{}
+	if err := folder.ReadInode(partitionPath, int64(sb.SInodeStart+destinationIndex*sb.SInodeSize)); err != nil {
+		return nil, err
+	}
+
+	if folder.IType != '0' {
+		return nil, fmt.Errorf("destination /%s is not a folder", strings.Join(destination, "/"))
+	}
+
+	if isSubPath(source, destination) {
+		return nil, fmt.Errorf("%s cannot be copied inside itself", sourceName)
+	}
+
+	target := append(append([]string{}, destination...), source[len(source)-1])
+	if sb.GetInodeIndex(partitionPath, target) != -1 {
+		return nil, fmt.Errorf("/%s already exists", strings.Join(target, "/"))
+	}
+
+	var skipped []string
+	if err := sb.CopyInode(partitionPath, sourceIndex, sourceName, target, uid, gid, &skipped); err != nil {
+		return nil, err
+	}
+
+	return skipped, nil
+}
+
+// isSubPath reports whether path is parent or is inside parent
+func isSubPath(parent, path []string) bool {
+	if len(path) < len(parent) {
+		return false
+	}
+
+	for i := range parent {
+		if parent[i] != path[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (cmd *Copy) Print() string {
+	if len(cmd.skipped) == 0 {
+		return fmt.Sprintf("%s copied to %s", cmd.Path, cmd.Destino)
+	}
+
+	return fmt.Sprintf("%s copied to %s, skipped without read permission: %s", cmd.Path, cmd.Destino, strings.Join(cmd.skipped, ", "))
+}
@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"backend/global"
+	"backend/structures"
+	"backend/utils"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+type Edit struct {
+	Path      string
+	Contenido string
+}
+
+func ParserEdit(tokens []string) (string, error) {
+	cmd := &Edit{}
+
+	args := strings.Join(tokens, " ")
+	re := regexp.MustCompile(`(?i)-path(?-i)="[^"]+"|(?i)-path(?-i)=\S+|(?i)-contenido(?-i)="[^"]+"|(?i)-contenido(?-i)=\S+`)
+	matches := re.FindAllString(args, -1)
+
+	for _, match := range matches {
+		key, value, err := utils.ParseToken(match)
+		if err != nil {
+			return "", err
+		}
+
+		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
+			value = strings.Trim(value, "\"")
+		}
+
+		switch key {
+		case "-path":
+			if value == "" {
+				return "", fmt.Errorf("invalid path: %s", value)
+			}
+			cmd.Path = value
+		case "-contenido":
+			if value == "" {
+				return "", fmt.Errorf("invalid content: %s", value)
+			}
+			cmd.Contenido = value
+		}
+	}
+
+	if cmd.Path == "" {
+		return "", fmt.Errorf("path is required")
+	}
+
+	if cmd.Contenido == "" {
+		return "", fmt.Errorf("contenido is required")
+	}
+
+	if err := cmd.commandEdit(); err != nil {
+		return "", err
+	}
+
+	return cmd.Print(), nil
+}
+
+func (cmd *Edit) commandEdit() error {
+	if !global.IsUserLogged() {
+		return fmt.Errorf("you must be logged in")
+	}
+
+	content, err := os.ReadFile(cmd.Contenido)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", cmd.Contenido, err)
+	}
+
+	mountedPartition, partitionPath, err := global.GetMountedPartition(global.LoggedPartition)
+	if err != nil {
+		return err
+	}
+
+	sb := &structures.SuperBlock{}
+	if err := sb.ReadSuperBlock(partitionPath, int64(mountedPartition.Start)); err != nil {
+		return err
+	}
+
+	if err := sb.CheckJournalSpace(partitionPath, cmd.Path, string(content)); err != nil {
+		return err
+	}
+
+	global.ParserUserData(sb.GetFile(partitionPath, 0, []string{"users.txt"}))
+
+	uid, gid, err := global.GetLoggedUserIDs()
+	if err != nil {
+		return err
+	}
+
+	filePath := splitPath(cmd.Path)
+	if err := checkProtectedPath(filePath, "edited"); err != nil {
+		return err
+	}
+
+	index, err := sb.CheckAccess(partitionPath, filePath, uid, gid, structures.PermissionWrite)
+	if err != nil {
+		return err
+	}
+
+	inode := &structures.Inode{}
+	if err := inode.ReadInode(partitionPath, int64(sb.SInodeStart+index*sb.SInodeSize)); err != nil {
+		return err
+	}
+
+	if inode.IType != '1' {
+		return fmt.Errorf("%s is not a file", cmd.Path)
+	}
+
+	if _, err := sb.WriteFile(partitionPath, 0, filePath, string(content)); err != nil {
+		return err
+	}
+
+	if err := sb.WriteSuperBlock(partitionPath, int64(mountedPartition.Start), int64(mountedPartition.Start+int32(binary.Size(sb)))); err != nil {
+		return err
+	}
+
+	if err := sb.AddJournal(partitionPath, "edit", cmd.Path, string(content)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (cmd *Edit) Print() string {
+	return fmt.Sprintf("file %s edited successfully", cmd.Path)
+}
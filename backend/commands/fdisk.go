@@ -1,8 +1,10 @@
 package commands
 
 import (
+	"backend/global"
 	"backend/structures"
 	"backend/utils"
+	"encoding/binary"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -10,22 +12,31 @@ import (
 )
 
 type FDisk struct {
-	Size int
-	Unit string
-	Path string
-	Type string
-	Fit  string
-	Name string
+	Size   int
+	Unit   string
+	Path   string
+	Type   string
+	Fit    string
+	Name   string
+	Delete string
+	Add    int
+	DryRun bool
+	start  int32
 }
 
 func ParserFDisk(tokens []string) (string, error) {
 	cmd := &FDisk{}
 
 	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`(?i)-size(?-i)=\d+|(?i)-unit(?-i)=[bBkKmM]|(?i)-fit(?-i)=[bBfF]{2}|(?i)-path(?-i)="[^"]+"|(?i)-path(?-i)=\S+|(?i)-type(?-i)=[pPeElL]|(?i)-name(?-i)="[^"]+"|(?i)-name(?-i)=\S+`)
+	re := regexp.MustCompile(`(?i)-size(?-i)=\d+|(?i)-unit(?-i)=[bBkKmM]|(?i)-fit(?-i)=[bBfFwW]{2}|(?i)-path(?-i)="[^"]+"|(?i)-path(?-i)=\S+|(?i)-type(?-i)=[pPeElL]|(?i)-name(?-i)="[^"]+"|(?i)-name(?-i)=\S+|(?i)-delete(?-i)=\S+|(?i)-add(?-i)=-?\d+|(?i)-dryrun`)
 	matches := re.FindAllString(args, -1)
 
 	for _, match := range matches {
+		if strings.ToLower(match) == "-dryrun" {
+			cmd.DryRun = true
+			continue
+		}
+
 		key, value, err := utils.ParseToken(match)
 		if err != nil {
 			return "", err
@@ -70,11 +81,64 @@ func ParserFDisk(tokens []string) (string, error) {
 				return "", fmt.Errorf("invalid name: %s", value)
 			}
 			cmd.Name = value
+		case "-delete":
+			value = strings.ToLower(value)
+			if value != "fast" && value != "full" {
+				return "", fmt.Errorf("invalid delete: %s", value)
+			}
+			cmd.Delete = value
+		case "-add":
+			add, err := strconv.Atoi(value)
+			if err != nil || add == 0 {
+				return "", fmt.Errorf("invalid add: %s", value)
+			}
+			cmd.Add = add
 		default:
 			return "", fmt.Errorf("unknown option: %s", key)
 		}
 	}
 
+	if cmd.Delete != "" && cmd.Add != 0 {
+		return "", fmt.Errorf("delete and add cannot be used together")
+	}
+
+	if cmd.Add != 0 {
+		if cmd.Unit == "" {
+			cmd.Unit = "K"
+		}
+
+		if cmd.Path == "" {
+			return "", fmt.Errorf("missing path")
+		}
+
+		if cmd.Name == "" {
+			return "", fmt.Errorf("missing name")
+		}
+
+		newSize, err := cmd.commandAdd()
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("FDISK\n Partition %s resized by %d%s\n New size: %d bytes\n Path: %s\n", cmd.Name, cmd.Add, cmd.Unit, newSize, cmd.Path), nil
+	}
+
+	if cmd.Delete != "" {
+		if cmd.Path == "" {
+			return "", fmt.Errorf("missing path")
+		}
+
+		if cmd.Name == "" {
+			return "", fmt.Errorf("missing name")
+		}
+
+		if err := cmd.commandDelete(); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("FDISK\n Partition %s deleted (%s)\n Path: %s\n", cmd.Name, cmd.Delete, cmd.Path), nil
+	}
+
 	if cmd.Size == 0 {
 		return "", fmt.Errorf("missing size")
 	}
@@ -104,6 +168,10 @@ func ParserFDisk(tokens []string) (string, error) {
 			err, cmd.Size, cmd.Unit, cmd.Path, cmd.Type, cmd.Fit, cmd.Name)
 	}
 
+	if cmd.DryRun {
+		return fmt.Sprintf("FDISK (dry run)\n Partition %s would start at byte %d\n Size: %d%s\n Type: %s\n Path: %s\n", cmd.Name, cmd.start, cmd.Size, cmd.Unit, cmd.Type, cmd.Path), nil
+	}
+
 	mbr := structures.MBR{}
 	if err := mbr.ReadMBR(cmd.Path); err != nil {
 		return "", err
@@ -147,10 +215,15 @@ func (cmd *FDisk) createPrimaryPartition(mbr *structures.MBR, sizeInBytes int) e
 		return err
 	}
 
-	if mbr.FreeNamePartition(cmd.Name) == false {
+	if cmd.nameExists(mbr) {
 		return fmt.Errorf("name already exists: %s", cmd.Name)
 	}
 
+	cmd.start = indexByte
+	if cmd.DryRun {
+		return nil
+	}
+
 	partition := &mbr.MbrPartition[indexPart]
 	partition.SetPartition(cmd.Type, cmd.Fit, indexByte, int32(sizeInBytes), cmd.Name)
 
@@ -171,10 +244,15 @@ func (cmd *FDisk) createExtendedPartition(mbr *structures.MBR, sizeInBytes int)
 		return err
 	}
 
-	if mbr.FreeNamePartition(cmd.Name) == false {
+	if cmd.nameExists(mbr) {
 		return fmt.Errorf("name already exists: %s", cmd.Name)
 	}
 
+	cmd.start = indexByte
+	if cmd.DryRun {
+		return nil
+	}
+
 	partition := &mbr.MbrPartition[indexPart]
 	partition.SetPartition(cmd.Type, cmd.Fit, indexByte, int32(sizeInBytes), cmd.Name)
 
@@ -197,36 +275,358 @@ func (cmd *FDisk) createLogicalPartition(mbr *structures.MBR, sizeInBytes int) e
 		return fmt.Errorf("extended partition does not exist")
 	}
 
-	start := int32(0)
-	partition := mbr.GetExtendedPartition()
-	start = partition.PartStart
+	if cmd.nameExists(mbr) {
+		return fmt.Errorf("name already exists: %s", cmd.Name)
+	}
+
+	extended := mbr.GetExtendedPartition()
+	extendedEnd := extended.PartStart + extended.PartSize
+
+	chain, err := structures.ReadEBRChain(cmd.Path, extended)
+	if err != nil {
+		return err
+	}
+
+	var logicals []structures.EBR
+	for _, node := range chain {
+		if !node.EBR.IsEmpty() {
+			logicals = append(logicals, node.EBR)
+		}
+	}
+
+	// Every logical partition needs room for its EBR, and the chain needs room for its closing EBR
+	ebrSize := int32(binary.Size(structures.EBR{}))
+	objects := structures.ConvertToObjects(logicals)
+	offset := structures.FindSpace(extended.PartFit, objects, ebrSize+int32(sizeInBytes), extended.PartStart, extendedEnd-ebrSize-1)
+
+	if offset == -1 {
+		return fmt.Errorf("no space available for logical partition")
+	}
+
+	cmd.start = offset + ebrSize
+	if cmd.DryRun {
+		return nil
+	}
+
+	closing := chain[len(chain)-1]
+	previous := -1
+	for i, node := range chain {
+		if node.Offset < int64(offset) {
+			previous = i
+		}
+	}
 
 	ebr := &structures.EBR{}
-	if err := ebr.ReadEBR(cmd.Path, int64(partition.PartStart)); err != nil {
+	ebr.SetEBR(cmd.Fit, offset+ebrSize, int32(sizeInBytes), -1, cmd.Name)
+
+	if int64(offset) == closing.Offset {
+		// Placed after the last logical partition, move the closing EBR behind the new one
+		ebr.PartNext = ebr.PartStart + ebr.PartSize
+
+		def := &structures.EBR{}
+		def.DefaultValue()
+
+		if err := def.WriteEBR(cmd.Path, int64(ebr.PartNext), int64(extendedEnd)); err != nil {
+			return err
+		}
+	} else if previous == -1 {
+		// Reuses the empty EBR at the start of the extended partition
+		ebr.PartNext = chain[0].EBR.PartNext
+	} else {
+		ebr.PartNext = chain[previous].EBR.PartNext
+	}
+
+	if previous != -1 {
+		prev := chain[previous].EBR
+		prev.PartNext = offset
+
+		if err := prev.WriteEBR(cmd.Path, chain[previous].Offset, int64(extendedEnd)); err != nil {
+			return err
+		}
+	}
+
+	if err := ebr.WriteEBR(cmd.Path, int64(offset), int64(extendedEnd)); err != nil {
 		return err
 	}
 
-	for ebr.PartNext != -1 {
-		start = ebr.PartNext
-		if err := ebr.ReadEBR(cmd.Path, int64(ebr.PartNext)); err != nil {
+	return nil
+}
+
+func (cmd *FDisk) commandDelete() error {
+	mbr := &structures.MBR{}
+	if err := mbr.ReadMBR(cmd.Path); err != nil {
+		return err
+	}
+
+	partition, _ := mbr.GetPartitionByName(cmd.Name)
+	if partition != nil && !partition.IsEmpty() {
+		return cmd.deletePartition(mbr, partition)
+	}
+
+	return cmd.deleteLogicalPartition(mbr)
+}
+
+func (cmd *FDisk) deletePartition(mbr *structures.MBR, partition *structures.Partition) error {
+	if global.IsPartitionMounted(cmd.Path, cmd.Name) {
+		return fmt.Errorf("partition %s is mounted, unmount it first", cmd.Name)
+	}
+
+	if partition.PartType == 'E' {
+		chain, err := structures.ReadEBRChain(cmd.Path, partition)
+		if err != nil {
+			return err
+		}
+
+		for _, node := range chain {
+			name := strings.TrimRight(string(node.EBR.PartName[:]), "\x00")
+			if !node.EBR.IsEmpty() && global.IsPartitionMounted(cmd.Path, name) {
+				return fmt.Errorf("logical partition %s is mounted, unmount it first", name)
+			}
+		}
+	}
+
+	if cmd.Delete == "full" {
+		if err := utils.FillWithZeros(cmd.Path, int64(partition.PartStart), int64(partition.PartSize)); err != nil {
 			return err
 		}
 	}
 
-	ebr.SetEBR(cmd.Fit, start+30, int32(sizeInBytes), start+30+int32(sizeInBytes), cmd.Name)
+	partition.DefaultValue()
 
-	if err := ebr.WriteEBR(cmd.Path, int64(ebr.PartStart-30), int64(partition.PartStart+partition.PartSize)); err != nil {
+	return mbr.WriteMBR(cmd.Path)
+}
+
+func (cmd *FDisk) deleteLogicalPartition(mbr *structures.MBR) error {
+	extended := mbr.GetExtendedPartition()
+	if extended == nil {
+		return fmt.Errorf("partition not found: %s", cmd.Name)
+	}
+
+	chain, err := structures.ReadEBRChain(cmd.Path, extended)
+	if err != nil {
 		return err
 	}
 
-	def := &structures.EBR{}
-	def.DefaultValue()
+	index := -1
+	for i, node := range chain {
+		if !node.EBR.IsEmpty() && strings.TrimRight(string(node.EBR.PartName[:]), "\x00") == cmd.Name {
+			index = i
+			break
+		}
+	}
+
+	if index == -1 {
+		return fmt.Errorf("partition not found: %s", cmd.Name)
+	}
+
+	if global.IsPartitionMounted(cmd.Path, cmd.Name) {
+		return fmt.Errorf("partition %s is mounted, unmount it first", cmd.Name)
+	}
+
+	target := chain[index]
+	extendedEnd := int64(extended.PartStart + extended.PartSize)
+	// The chain is closed by an empty EBR that must stay right after the last logical partition
+	isLast := index == len(chain)-2 && chain[len(chain)-1].EBR.IsEmpty()
 
-	if err := def.WriteEBR(cmd.Path, int64(ebr.PartNext), int64(partition.PartStart+partition.PartSize)); err != nil {
+	if cmd.Delete == "full" {
+		size := int64(target.EBR.PartStart+target.EBR.PartSize) - target.Offset
+		if err := utils.FillWithZeros(cmd.Path, target.Offset, size); err != nil {
+			return err
+		}
+	}
+
+	if index == 0 {
+		head := &structures.EBR{}
+		head.DefaultValue()
+		if !isLast {
+			head.PartNext = target.EBR.PartNext
+		}
+		return head.WriteEBR(cmd.Path, target.Offset, extendedEnd)
+	}
+
+	previous := chain[index-1]
+
+	if !isLast {
+		previous.EBR.PartNext = target.EBR.PartNext
+		return previous.EBR.WriteEBR(cmd.Path, previous.Offset, extendedEnd)
+	}
+
+	if previous.EBR.IsEmpty() {
+		previous.EBR.PartNext = -1
+		return previous.EBR.WriteEBR(cmd.Path, previous.Offset, extendedEnd)
+	}
+
+	previous.EBR.PartNext = previous.EBR.PartStart + previous.EBR.PartSize
+	if err := previous.EBR.WriteEBR(cmd.Path, previous.Offset, extendedEnd); err != nil {
 		return err
 	}
 
-	return nil
+	closing := &structures.EBR{}
+	closing.DefaultValue()
+
+	return closing.WriteEBR(cmd.Path, int64(previous.EBR.PartNext), extendedEnd)
+}
+
+func (cmd *FDisk) commandAdd() (int32, error) {
+	addInBytes, err := utils.ConvertToBytes(cmd.Add, cmd.Unit)
+	if err != nil {
+		return 0, err
+	}
+
+	mbr := &structures.MBR{}
+	if err := mbr.ReadMBR(cmd.Path); err != nil {
+		return 0, err
+	}
+
+	partition, _ := mbr.GetPartitionByName(cmd.Name)
+	if partition != nil && !partition.IsEmpty() {
+		return cmd.resizePartition(mbr, partition, int32(addInBytes))
+	}
+
+	return cmd.resizeLogicalPartition(mbr, int32(addInBytes))
+}
+
+func (cmd *FDisk) resizePartition(mbr *structures.MBR, partition *structures.Partition, addInBytes int32) (int32, error) {
+	newSize := partition.PartSize + addInBytes
+
+	if addInBytes > 0 {
+		objects := structures.ConvertToObjects(mbr.MbrPartition[:])
+		free := structures.FreeSpaceAfter(objects, partition.PartStart+partition.PartSize, int32(153), mbr.MbrSize-1)
+		if free < addInBytes {
+			return 0, fmt.Errorf("not enough free space after partition %s: %d bytes available", cmd.Name, free)
+		}
+	} else {
+		minimum, err := cmd.minimumSize(partition.PartStart)
+		if err != nil {
+			return 0, err
+		}
+
+		if partition.PartType == 'E' {
+			chain, err := structures.ReadEBRChain(cmd.Path, partition)
+			if err != nil {
+				return 0, err
+			}
+
+			closing := chain[len(chain)-1]
+			minimum = max(minimum, int32(closing.Offset)+int32(binary.Size(closing.EBR))-partition.PartStart)
+		}
+
+		if newSize < minimum {
+			return 0, fmt.Errorf("cannot shrink partition %s below %d bytes", cmd.Name, minimum)
+		}
+	}
+
+	partition.PartSize = newSize
+
+	if err := mbr.WriteMBR(cmd.Path); err != nil {
+		return 0, err
+	}
+
+	return newSize, nil
+}
+
+func (cmd *FDisk) resizeLogicalPartition(mbr *structures.MBR, addInBytes int32) (int32, error) {
+	extended := mbr.GetExtendedPartition()
+	if extended == nil {
+		return 0, fmt.Errorf("partition not found: %s", cmd.Name)
+	}
+
+	chain, err := structures.ReadEBRChain(cmd.Path, extended)
+	if err != nil {
+		return 0, err
+	}
+
+	index := -1
+	var logicals []structures.EBR
+	for i, node := range chain {
+		if node.EBR.IsEmpty() {
+			continue
+		}
+		logicals = append(logicals, node.EBR)
+		if strings.TrimRight(string(node.EBR.PartName[:]), "\x00") == cmd.Name {
+			index = i
+		}
+	}
+
+	if index == -1 {
+		return 0, fmt.Errorf("partition not found: %s", cmd.Name)
+	}
+
+	target := chain[index]
+	ebrSize := int32(binary.Size(target.EBR))
+	extendedEnd := extended.PartStart + extended.PartSize
+	isLast := index == len(chain)-2 && chain[len(chain)-1].EBR.IsEmpty()
+	newSize := target.EBR.PartSize + addInBytes
+
+	if addInBytes > 0 {
+		end := extendedEnd - 1
+		if isLast {
+			// Leave room for the empty EBR that closes the chain
+			end -= ebrSize
+		}
+
+		objects := structures.ConvertToObjects(logicals)
+		free := structures.FreeSpaceAfter(objects, target.EBR.PartStart+target.EBR.PartSize, extended.PartStart, end)
+		if free < addInBytes {
+			return 0, fmt.Errorf("not enough free space after partition %s: %d bytes available", cmd.Name, free)
+		}
+	} else {
+		minimum, err := cmd.minimumSize(target.EBR.PartStart)
+		if err != nil {
+			return 0, err
+		}
+
+		if newSize < minimum {
+			return 0, fmt.Errorf("cannot shrink partition %s below %d bytes", cmd.Name, minimum)
+		}
+	}
+
+	target.EBR.PartSize = newSize
+
+	if isLast {
+		target.EBR.PartNext = target.EBR.PartStart + target.EBR.PartSize
+
+		closing := &structures.EBR{}
+		closing.DefaultValue()
+
+		if err := closing.WriteEBR(cmd.Path, int64(target.EBR.PartNext), int64(extendedEnd)); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := target.EBR.WriteEBR(cmd.Path, target.Offset, int64(extendedEnd)); err != nil {
+		return 0, err
+	}
+
+	return newSize, nil
+}
+
+// minimumSize returns the smallest size a partition can take without cutting its filesystem
+func (cmd *FDisk) minimumSize(start int32) (int32, error) {
+	sb := &structures.SuperBlock{}
+	if err := sb.ReadSuperBlock(cmd.Path, int64(start)); err != nil {
+		return 0, err
+	}
+
+	if !sb.IsFormatted() {
+		return 1, nil
+	}
+
+	return sb.BlocksEnd() - start, nil
+}
+
+func (cmd *FDisk) nameExists(mbr *structures.MBR) bool {
+	if !mbr.FreeNamePartition(cmd.Name) {
+		return true
+	}
+
+	extended := mbr.GetExtendedPartition()
+	if extended == nil {
+		return false
+	}
+
+	_, err := structures.FindLogicalPartition(cmd.Path, extended, cmd.Name)
+	return err == nil
 }
 
 func (cmd *FDisk) findAvailableSpace(mbr *structures.MBR, sizeInBytes int) (int, int32, error) {
@@ -236,7 +636,7 @@ func (cmd *FDisk) findAvailableSpace(mbr *structures.MBR, sizeInBytes int) (int,
 	}
 
 	objects := structures.ConvertToObjects(mbr.MbrPartition[:])
-	indexByte := structures.FirstFit(objects, int32(sizeInBytes), int32(153), mbr.MbrSize)
+	indexByte := structures.FindSpace(mbr.MbrDiskFit, objects, int32(sizeInBytes), int32(153), mbr.MbrSize-1)
 
 	if indexByte == -1 {
 		return -1, -1, fmt.Errorf("no space available for partition")
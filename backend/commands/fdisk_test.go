@@ -0,0 +1,231 @@
+package commands
+
+import (
+	"backend/structures"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// partitionLayout returns the start and size of every primary, extended and logical partition of the disk
+func partitionLayout(t *testing.T, path string) map[string][2]int32 {
+	t.Helper()
+
+	mbr := &structures.MBR{}
+	if err := mbr.ReadMBR(path); err != nil {
+		t.Fatal(err)
+	}
+
+	layout := make(map[string][2]int32)
+	for _, partition := range mbr.MbrPartition {
+		if partition.IsEmpty() {
+			continue
+		}
+		layout[strings.TrimRight(string(partition.PartName[:]), "\x00")] = [2]int32{partition.PartStart, partition.PartSize}
+	}
+
+	extended := mbr.GetExtendedPartition()
+	if extended == nil {
+		return layout
+	}
+
+	chain, err := structures.ReadEBRChain(path, extended)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, node := range chain {
+		if node.EBR.IsEmpty() {
+			continue
+		}
+		layout[strings.TrimRight(string(node.EBR.PartName[:]), "\x00")] = [2]int32{node.EBR.PartStart, node.EBR.PartSize}
+	}
+
+	return layout
+}
+
+func TestFDisk(t *testing.T) {
+	// P1 at 153, P2 at 353, P3 at 403 and P4 at 503 leave holes of 200, 100 and 471 bytes once P1 and P3 are deleted
+	holes := []string{
+		"fdisk -type=P -unit=b -name=P1 -size=200 -path={dir}/disk.mia",
+		"fdisk -type=P -unit=b -name=P2 -size=50 -path={dir}/disk.mia",
+		"fdisk -type=P -unit=b -name=P3 -size=100 -path={dir}/disk.mia",
+		"fdisk -type=P -unit=b -name=P4 -size=50 -path={dir}/disk.mia",
+		"fdisk -delete=fast -name=P1 -path={dir}/disk.mia",
+		"fdisk -delete=fast -name=P3 -path={dir}/disk.mia",
+	}
+	logicals := []string{
+		"fdisk -type=E -unit=b -name=E1 -size=600 -path={dir}/disk.mia",
+		"fdisk -type=L -unit=b -name=L1 -size=100 -path={dir}/disk.mia",
+		"fdisk -type=L -unit=b -name=L2 -size=100 -path={dir}/disk.mia",
+	}
+
+	tests := []struct {
+		name    string
+		disk    string
+		setup   []string
+		command string
+		wantErr bool
+		want    map[string][2]int32
+		absent  []string
+		// Partitions left as they were and size changes relative to the layout before the command
+		unchanged []string
+		grown     map[string]int32
+	}{
+		{
+			name:    "first fit takes the first hole",
+			disk:    "mkdisk -size=1 -unit=K -fit=FF -path={dir}/disk.mia",
+			setup:   holes,
+			command: "fdisk -type=P -unit=b -name=N -size=80 -path={dir}/disk.mia",
+			want:    map[string][2]int32{"N": {153, 80}},
+		},
+		{
+			name:    "best fit takes the smallest hole",
+			disk:    "mkdisk -size=1 -unit=K -fit=BF -path={dir}/disk.mia",
+			setup:   holes,
+			command: "fdisk -type=P -unit=b -name=N -size=80 -path={dir}/disk.mia",
+			want:    map[string][2]int32{"N": {403, 80}},
+		},
+		{
+			name:    "worst fit takes the largest hole",
+			disk:    "mkdisk -size=1 -unit=K -fit=WF -path={dir}/disk.mia",
+			setup:   holes,
+			command: "fdisk -type=P -unit=b -name=N -size=80 -path={dir}/disk.mia",
+			want:    map[string][2]int32{"N": {553, 80}},
+		},
+		{
+			name:    "no hole is big enough",
+			disk:    "mkdisk -size=1 -unit=K -fit=FF -path={dir}/disk.mia",
+			setup:   holes,
+			command: "fdisk -type=P -unit=b -name=N -size=500 -path={dir}/disk.mia",
+			wantErr: true,
+			absent:  []string{"N"},
+		},
+		{
+			name:    "dry run leaves the disk untouched",
+			disk:    "mkdisk -size=1 -unit=K -fit=BF -path={dir}/disk.mia",
+			setup:   holes,
+			command: "fdisk -type=P -unit=b -name=N -size=80 -dryrun -path={dir}/disk.mia",
+			absent:  []string{"N"},
+		},
+		{
+			name:    "delete primary",
+			disk:    "mkdisk -size=1 -unit=K -path={dir}/disk.mia",
+			setup:   holes[:2],
+			command: "fdisk -delete=full -name=P1 -path={dir}/disk.mia",
+			want:    map[string][2]int32{"P2": {353, 50}},
+			absent:  []string{"P1"},
+		},
+		{
+			name: "delete mounted partition",
+			disk: "mkdisk -size=1 -unit=K -path={dir}/disk.mia",
+			setup: append(append([]string{}, holes[:1]...),
+				"mount -path={dir}/disk.mia -name=P1"),
+			command: "fdisk -delete=fast -name=P1 -path={dir}/disk.mia",
+			wantErr: true,
+			want:    map[string][2]int32{"P1": {153, 200}},
+		},
+		{
+			name:    "delete missing partition",
+			disk:    "mkdisk -size=1 -unit=K -path={dir}/disk.mia",
+			setup:   holes[:1],
+			command: "fdisk -delete=fast -name=P9 -path={dir}/disk.mia",
+			wantErr: true,
+		},
+		{
+			name:      "delete logical",
+			disk:      "mkdisk -size=1 -unit=K -path={dir}/disk.mia",
+			setup:     logicals,
+			command:   "fdisk -delete=fast -name=L1 -path={dir}/disk.mia",
+			absent:    []string{"L1"},
+			unchanged: []string{"E1", "L2"},
+		},
+		{
+			name:    "grow into free space",
+			disk:    "mkdisk -size=1 -unit=K -path={dir}/disk.mia",
+			setup:   holes[:1],
+			command: "fdisk -add=300 -unit=b -name=P1 -path={dir}/disk.mia",
+			want:    map[string][2]int32{"P1": {153, 500}},
+		},
+		{
+			name:    "grow over the next partition",
+			disk:    "mkdisk -size=1 -unit=K -path={dir}/disk.mia",
+			setup:   holes[:2],
+			command: "fdisk -add=1 -unit=b -name=P1 -path={dir}/disk.mia",
+			wantErr: true,
+			want:    map[string][2]int32{"P1": {153, 200}},
+		},
+		{
+			name:    "shrink",
+			disk:    "mkdisk -size=1 -unit=K -path={dir}/disk.mia",
+			setup:   holes[:1],
+			command: "fdisk -add=-50 -unit=b -name=P1 -path={dir}/disk.mia",
+			want:    map[string][2]int32{"P1": {153, 150}},
+		},
+		{
+			name: "shrink below the filesystem",
+			disk: "mkdisk -size=1 -unit=M -path={dir}/disk.mia",
+			setup: []string{
+				"fdisk -type=P -unit=k -name=P1 -size=300 -path={dir}/disk.mia",
+				"mount -path={dir}/disk.mia -name=P1",
+				"mkfs -id={id}",
+			},
+			command: "fdisk -add=-200 -unit=k -name=P1 -path={dir}/disk.mia",
+			wantErr: true,
+			want:    map[string][2]int32{"P1": {153, 300 * 1024}},
+		},
+		{
+			name:    "grow logical",
+			disk:    "mkdisk -size=1 -unit=K -path={dir}/disk.mia",
+			setup:   logicals[:2],
+			command: "fdisk -add=50 -unit=b -name=L1 -path={dir}/disk.mia",
+			grown:   map[string]int32{"L1": 50},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			runCommand(t, dir, "", tt.disk)
+
+			id := ""
+			for _, line := range tt.setup {
+				result := runCommand(t, dir, id, line)
+				if strings.HasPrefix(line, "mount") {
+					id = result[strings.LastIndex(result, " ")+1:]
+					mounted := id
+					t.Cleanup(func() { _, _ = tryCommand(dir, mounted, "unmount -id={id}") })
+				}
+			}
+
+			before := partitionLayout(t, filepath.Join(dir, "disk.mia"))
+
+			_, err := tryCommand(dir, id, tt.command)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("%s: error = %v, wantErr %v", tt.command, err, tt.wantErr)
+			}
+
+			layout := partitionLayout(t, filepath.Join(dir, "disk.mia"))
+			for name, want := range tt.want {
+				if got, ok := layout[name]; !ok || got != want {
+					t.Errorf("%s = %v, want %v", name, got, want)
+				}
+			}
+			for _, name := range tt.absent {
+				if _, ok := layout[name]; ok {
+					t.Errorf("%s still exists", name)
+				}
+			}
+			for _, name := range tt.unchanged {
+				if layout[name] != before[name] {
+					t.Errorf("%s = %v, want %v", name, layout[name], before[name])
+				}
+			}
+			for name, add := range tt.grown {
+				if got, want := layout[name], [2]int32{before[name][0], before[name][1] + add}; got != want {
+					t.Errorf("%s = %v, want %v", name, got, want)
+				}
+			}
+		})
+	}
+}
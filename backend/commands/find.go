@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"backend/global"
+	"backend/structures"
+	"backend/utils"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type Find struct {
+	Path  string
+	Name  string
+	lines []string
+}
+
+func ParserFind(tokens []string) (string, error) {
+	cmd := &Find{}
+
+	args := strings.Join(tokens, " ")
+	re := regexp.MustCompile(`(?i)-path(?-i)="[^"]+"|(?i)-path(?-i)=\S+|(?i)-name(?-i)="[^"]+"|(?i)-name(?-i)=\S+`)
+	matches := re.FindAllString(args, -1)
+
+	for _, match := range matches {
+		key, value, err := utils.ParseToken(match)
+		if err != nil {
+			return "", err
+		}
+
+		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
+			value = strings.Trim(value, "\"")
+		}
+
+		switch key {
+		case "-path":
+			if value == "" {
+				return "", fmt.Errorf("invalid path: %s", value)
+			}
+			cmd.Path = value
+		case "-name":
+			if value == "" {
+				return "", fmt.Errorf("invalid name: %s", value)
+			}
+			cmd.Name = value
+		}
+	}
+
+	if cmd.Path == "" {
+		return "", fmt.Errorf("path is required")
+	}
+
+	if cmd.Name == "" {
+		return "", fmt.Errorf("name is required")
+	}
+
+	if err := cmd.commandFind(); err != nil {
+		return "", err
+	}
+
+	return cmd.Print(), nil
+}
+
+func (cmd *Find) commandFind() error {
+	if !global.IsUserLogged() {
+		return fmt.Errorf("you must be logged in")
+	}
+
+	mountedPartition, partitionPath, err := global.GetMountedPartition(global.LoggedPartition)
+	if err != nil {
+		return err
+	}
+
+	sb := &structures.SuperBlock{}
+	if err := sb.ReadSuperBlock(partitionPath, int64(mountedPartition.Start)); err != nil {
+		return err
+	}
+
+	global.ParserUserData(sb.GetFile(partitionPath, 0, []string{"users.txt"}))
+
+	uid, gid, err := global.GetLoggedUserIDs()
+	if err != nil {
+		return err
+	}
+
+	index, err := sb.CheckAccess(partitionPath, splitPath(cmd.Path), uid, gid, structures.PermissionRead|structures.PermissionExecute)
+	if err != nil {
+		return err
+	}
+
+	inode := &structures.Inode{}
+	if err := inode.ReadInode(partitionPath, int64(sb.SInodeStart+index*sb.SInodeSize)); err != nil {
+		return err
+	}
+
+	if inode.IType != '0' {
+		return fmt.Errorf("%s is not a folder", cmd.Path)
+	}
+
+	lines, err := findInFolder(sb, partitionPath, inode, cmd.Name, uid, gid, 1)
+	if err != nil {
+		return err
+	}
+
+	if len(lines) > 0 {
+		cmd.lines = append([]string{"/" + strings.Join(splitPath(cmd.Path), "/")}, lines...)
+	}
+
+	return nil
+}
+
+// findInFolder returns the indented lines of the entries under folder that match the pattern,
+// together with the folders leading to them
+func findInFolder(sb *structures.SuperBlock, partitionPath string, folder *structures.Inode, pattern string, uid, gid int32, depth int) ([]string, error) {
+	var lines []string
+	indent := strings.Repeat("  ", depth)
+
+	for _, entry := range sb.GetFolderEntries(partitionPath, folder) {
+		name := strings.TrimRight(string(entry.BName[:]), "\x00")
+
+		child := &structures.Inode{}
+		if err := child.ReadInode(partitionPath, int64(sb.SInodeStart+entry.BInode*sb.SInodeSize)); err != nil {
+			return nil, err
+		}
+
+		var childLines []string
+		if child.IType == '0' && child.HasPermission(uid, gid, structures.PermissionRead|structures.PermissionExecute) {
+			found, err := findInFolder(sb, partitionPath, child, pattern, uid, gid, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			childLines = found
+		}
+
+		if matchWildcard(pattern, name) || len(childLines) > 0 {
+			lines = append(lines, indent+name)
+			lines = append(lines, childLines...)
+		}
+	}
+
+	return lines, nil
+}
+
+// matchWildcard matches name against a pattern where * is any sequence of characters and ? is one character
+func matchWildcard(pattern, name string) bool {
+	if pattern == "" {
+		return name == ""
+	}
+
+	switch pattern[0] {
+	case '*':
+		for i := 0; i <= len(name); i++ {
+			if matchWildcard(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	case '?':
+		return name != "" && matchWildcard(pattern[1:], name[1:])
+	default:
+		return name != "" && pattern[0] == name[0] && matchWildcard(pattern[1:], name[1:])
+	}
+}
+
+func (cmd *Find) Print() string {
+	if len(cmd.lines) == 0 {
+		return fmt.Sprintf("no matches for %s in %s", cmd.Name, cmd.Path)
+	}
+
+	return strings.Join(cmd.lines, "\n")
+}
@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"backend/global"
+	"backend/structures"
+	"backend/utils"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Journaling struct {
+	Id      string
+	entries []structures.JournalEntry
+}
+
+var journalHeaders = []string{"#", "Operacion", "Path", "Contenido", "Fecha"}
+
+func ParserJournaling(tokens []string) (string, error) {
+	cmd := &Journaling{}
+
+	args := strings.Join(tokens, " ")
+	re := regexp.MustCompile(`(?i)-id(?-i)="[^"]+"|(?i)-id(?-i)=\S+`)
+	matches := re.FindAllString(args, -1)
+
+	for _, match := range matches {
+		key, value, err := utils.ParseToken(match)
+		if err != nil {
+			return "", err
+		}
+
+		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
+			value = strings.Trim(value, "\"")
+		}
+
+		switch key {
+		case "-id":
+			if value == "" {
+				return "", fmt.Errorf("invalid id: %s", value)
+			}
+			cmd.Id = value
+		}
+	}
+
+	if cmd.Id == "" {
+		return "", fmt.Errorf("missing id")
+	}
+
+	if err := cmd.commandJournaling(); err != nil {
+		return "", err
+	}
+
+	return cmd.Print(), nil
+}
+
+func (cmd *Journaling) commandJournaling() error {
+	entries, err := readJournalEntries(cmd.Id)
+	if err != nil {
+		return err
+	}
+
+	cmd.entries = entries
+
+	return nil
+}
+
+func readJournalEntries(id string) ([]structures.JournalEntry, error) {
+	partition, path, err := global.GetMountedPartition(id)
+	if err != nil {
+		return nil, err
+	}
+
+	sb := &structures.SuperBlock{}
+	if err := sb.ReadSuperBlock(path, int64(partition.Start)); err != nil {
+		return nil, err
+	}
+
+	if !sb.IsFormatted() {
+		return nil, fmt.Errorf("partition %s is not formatted", id)
+	}
+
+	if !sb.HasJournal() {
+		return nil, fmt.Errorf("partition %s is not ext3, it has no journal", id)
+	}
+
+	return sb.GetJournalEntries(path)
+}
+
+func journalRows(entries []structures.JournalEntry) [][]string {
+	var rows [][]string
+	for _, entry := range entries {
+		rows = append(rows, []string{
+			strconv.Itoa(int(entry.Count)),
+			entry.Operation,
+			entry.Path,
+			strings.ReplaceAll(entry.Content, "\n", "\\n"),
+			time.Unix(int64(entry.Date), 0).Format("02/01/2006 15:04"),
+		})
+	}
+	return rows
+}
+
+func (cmd *Journaling) Print() string {
+	if len(cmd.entries) == 0 {
+		return fmt.Sprintf("journal of partition %s is empty", cmd.Id)
+	}
+
+	return fmt.Sprintf("Journal of partition %s:\n%s", cmd.Id, generateTextTable(journalHeaders, journalRows(cmd.entries)))
+}
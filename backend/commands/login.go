@@ -4,6 +4,7 @@ import (
 	"backend/global"
 	"backend/structures"
 	"backend/utils"
+	"encoding/binary"
 	"fmt"
 	"regexp"
 	"strings"
@@ -83,7 +84,7 @@ func (cmd *Login) commandLogin() error {
 	}
 
 	sb := &structures.SuperBlock{}
-	if err := sb.ReadSuperBlock(partitionPath, int64(mountedPartition.PartStart)); err != nil {
+	if err := sb.ReadSuperBlock(partitionPath, int64(mountedPartition.Start)); err != nil {
 		return err
 	}
 
@@ -97,6 +98,34 @@ func (cmd *Login) commandLogin() error {
 		return err
 	}
 
+	// Recovery replays the operations that follow as this user, the record is only needed when it
+	// changes the session recovery is in
+	if user, umask, err := journaledSession(sb, partitionPath); err == nil && (user != cmd.User || umask != structures.DefaultUmask) {
+		_ = sb.AddJournal(partitionPath, "login", "/", cmd.User)
+	}
+
+	hash, err := global.UpgradePassword(cmd.User, cmd.Pass)
+	if err != nil || hash == "" {
+		return err
+	}
+
+	// The password stays in plain text until a login finds room in the journal to record the change
+	if err := sb.CheckJournalSpace(partitionPath, "/users.txt", cmd.User+","+hash); err != nil {
+		return nil
+	}
+
+	if _, err := sb.WriteFile(partitionPath, int32(0), array, global.ConvertToString()); err != nil {
+		return err
+	}
+
+	if err := sb.WriteSuperBlock(partitionPath, int64(mountedPartition.Start), int64(mountedPartition.Start+int32(binary.Size(sb)))); err != nil {
+		return err
+	}
+
+	if err := sb.AddJournal(partitionPath, "chpass", "/users.txt", cmd.User+","+hash); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"backend/global"
+	"backend/structures"
+	"backend/utils"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type Loss struct {
+	Id string
+}
+
+func ParserLoss(tokens []string) (string, error) {
+	cmd := &Loss{}
+
+	args := strings.Join(tokens, " ")
+	re := regexp.MustCompile(`(?i)-id(?-i)="[^"]+"|(?i)-id(?-i)=\S+`)
+	matches := re.FindAllString(args, -1)
+
+	for _, match := range matches {
+		key, value, err := utils.ParseToken(match)
+		if err != nil {
+			return "", err
+		}
+
+		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
+			value = strings.Trim(value, "\"")
+		}
+
+		switch key {
+		case "-id":
+			if value == "" {
+				return "", fmt.Errorf("invalid id: %s", value)
+			}
+			cmd.Id = value
+		}
+	}
+
+	if cmd.Id == "" {
+		return "", fmt.Errorf("missing id")
+	}
+
+	if err := cmd.commandLoss(); err != nil {
+		return "", err
+	}
+
+	return cmd.Print(), nil
+}
+
+func (cmd *Loss) commandLoss() error {
+	partition, path, err := global.GetMountedPartition(cmd.Id)
+	if err != nil {
+		return err
+	}
+
+	sb := &structures.SuperBlock{}
+	if err := sb.ReadSuperBlock(path, int64(partition.Start)); err != nil {
+		return err
+	}
+
+	if !sb.IsFormatted() {
+		return fmt.Errorf("partition %s is not formatted", cmd.Id)
+	}
+
+	if !sb.HasJournal() {
+		return fmt.Errorf("partition %s is not ext3, it has no journal", cmd.Id)
+	}
+
+	// Only the superblock and the journal survive
+	return utils.FillWithZeros(path, int64(sb.SBMInodeStart), int64(sb.BlocksEnd()-sb.SBMInodeStart))
+}
+
+func (cmd *Loss) Print() string {
+	return fmt.Sprintf("bitmaps, inodes and blocks of partition %s were lost", cmd.Id)
+}
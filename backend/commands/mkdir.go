@@ -74,7 +74,11 @@ func (cmd *MkDIR) commandMkDIR() error {
 	}
 
 	sb := &structures.SuperBlock{}
-	if err := sb.ReadSuperBlock(partitionPath, int64(mountedPartition.PartStart)); err != nil {
+	if err := sb.ReadSuperBlock(partitionPath, int64(mountedPartition.Start)); err != nil {
+		return err
+	}
+
+	if err := sb.CheckJournalSpace(partitionPath, cmd.Path, ""); err != nil {
 		return err
 	}
 
@@ -86,11 +90,30 @@ func (cmd *MkDIR) commandMkDIR() error {
 		}
 	}
 
-	if err := sb.CreateNewInode(partitionPath, result, 0, false, cmd.P); err != nil {
+	global.ParserUserData(sb.GetFile(partitionPath, 0, []string{"users.txt"}))
+
+	uid, gid, err := global.GetLoggedUserIDs()
+	if err != nil {
+		return err
+	}
+
+	if err := checkProtectedPath(result, "created"); err != nil {
+		return err
+	}
+
+	if err := sb.CheckCreate(partitionPath, result, uid, gid); err != nil {
+		return err
+	}
+
+	if err := sb.CreateNewInode(partitionPath, result, 0, false, cmd.P, uid, gid, global.Umask); err != nil {
+		return err
+	}
+
+	if err := sb.WriteSuperBlock(partitionPath, int64(mountedPartition.Start), int64(mountedPartition.Start+int32(binary.Size(sb)))); err != nil {
 		return err
 	}
 
-	if err := sb.WriteSuperBlock(partitionPath, int64(mountedPartition.PartStart), int64(mountedPartition.PartStart+int32(binary.Size(sb)))); err != nil {
+	if err := sb.AddJournal(partitionPath, "mkdir", cmd.Path, ""); err != nil {
 		return err
 	}
 
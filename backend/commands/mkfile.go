@@ -90,7 +90,7 @@ func (cmd *MkFile) commandMkFile() error {
 	}
 
 	sb := &structures.SuperBlock{}
-	if err := sb.ReadSuperBlock(partitionPath, int64(mountedPartition.PartStart)); err != nil {
+	if err := sb.ReadSuperBlock(partitionPath, int64(mountedPartition.Start)); err != nil {
 		return err
 	}
 
@@ -102,28 +102,51 @@ func (cmd *MkFile) commandMkFile() error {
 		}
 	}
 
-	if err := sb.CreateNewInode(partitionPath, result, 0, true, cmd.R); err != nil {
+	global.ParserUserData(sb.GetFile(partitionPath, 0, []string{"users.txt"}))
+
+	uid, gid, err := global.GetLoggedUserIDs()
+	if err != nil {
 		return err
 	}
 
-	cont := generateNumberString(cmd.Size)
-	if _, err := sb.WriteFile(partitionPath, int32(0), result, cont); err != nil {
+	if err := checkProtectedPath(result, "created"); err != nil {
 		return err
 	}
 
+	if err := sb.CheckCreate(partitionPath, result, uid, gid); err != nil {
+		return err
+	}
+
+	// The journal keeps the size of generated files instead of their digits
+	cont := generateNumberString(cmd.Size)
+	record := fmt.Sprintf("-size=%d", cmd.Size)
 	if cmd.Cont != "" {
 		content, err := ioutil.ReadFile(cmd.Cont)
 		if err != nil {
 			log.Fatalf("Error al leer el archivo: %v", err)
 		}
 
-		fileContent := string(content)
-		if _, err := sb.WriteFile(partitionPath, int32(0), result, fileContent); err != nil {
-			return err
-		}
+		cont = string(content)
+		record = "-cont=" + cont
+	}
+
+	if err := sb.CheckJournalSpace(partitionPath, cmd.Path, record); err != nil {
+		return err
 	}
 
-	if err := sb.WriteSuperBlock(partitionPath, int64(mountedPartition.PartStart), int64(mountedPartition.PartStart+int32(binary.Size(sb)))); err != nil {
+	if err := sb.CreateNewInode(partitionPath, result, 0, true, cmd.R, uid, gid, global.Umask); err != nil {
+		return err
+	}
+
+	if _, err := sb.WriteFile(partitionPath, int32(0), result, cont); err != nil {
+		return err
+	}
+
+	if err := sb.WriteSuperBlock(partitionPath, int64(mountedPartition.Start), int64(mountedPartition.Start+int32(binary.Size(sb)))); err != nil {
+		return err
+	}
+
+	if err := sb.AddJournal(partitionPath, "mkfile", cmd.Path, record); err != nil {
 		return err
 	}
 
@@ -139,6 +162,23 @@ func generateNumberString(n int) string {
 	return strings.Repeat(base, repeatCount) + base[:remainder]
 }
 
+// journaledContent returns the content of a file from its mkfile record in the journal
+func journaledContent(record string) (string, error) {
+	if size, found := strings.CutPrefix(record, "-size="); found {
+		n, err := strconv.Atoi(size)
+		if err != nil || n < 0 {
+			return "", fmt.Errorf("invalid size: %s", size)
+		}
+		return generateNumberString(n), nil
+	}
+
+	if cont, found := strings.CutPrefix(record, "-cont="); found {
+		return cont, nil
+	}
+
+	return "", fmt.Errorf("invalid mkfile entry: %s", record)
+}
+
 func (cmd *MkFile) Print() string {
 	return fmt.Sprintf("File created successfully in %s", cmd.Path)
 }
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateExisting(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		path    string
+	}{
+		{name: "mkfile over users.txt", command: "mkfile -path=/users.txt -cont={dir}/evil.txt", path: "/users.txt"},
+		{name: "mkdir over users.txt", command: "mkdir -path=/users.txt", path: "/users.txt"},
+		{name: "mkfile over a root file", command: "mkfile -path=/locked/f -cont={dir}/evil.txt", path: "/locked/f"},
+		{name: "mkdir over a root file", command: "mkdir -path=/locked/f", path: "/locked/f"},
+		{name: "mkfile over a folder", command: "mkfile -path=/locked -size=5", path: "/locked"},
+		{name: "mkdir over a folder", command: "mkdir -path=/shared/ana", path: "/shared/ana"},
+		{name: "mkfile -r over a file", command: "mkfile -r -path=/shared/ana/notes.txt -size=5", path: "/shared/ana/notes.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, id := newTestPartition(t)
+			if err := os.WriteFile(filepath.Join(dir, "evil.txt"), []byte("1,G,root\n1,U,root,root,pwned\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, line := range []string{
+				"mkgrp -name=dev",
+				"mkusr -user=ana -pass=1 -grp=dev",
+				"mkdir -path=/locked",
+				"mkfile -path=/locked/f -size=10",
+				"mkdir -path=/shared",
+				"chmod -path=/shared -ugo=777",
+				"logout",
+				"login -user=ana -pass=1 -id={id}",
+				"mkdir -path=/shared/ana",
+				"mkfile -path=/shared/ana/notes.txt -size=10",
+			} {
+				runCommand(t, dir, id, line)
+			}
+			want := snapshot(t, id)
+
+			if _, err := tryCommand(dir, id, tt.command); err == nil {
+				t.Fatalf("%s: expected an error, %s already exists", tt.command, tt.path)
+			}
+
+			if got := snapshot(t, id); got != want {
+				t.Errorf("partition changed\ngot:\n%s\nwant:\n%s", got, want)
+			}
+
+			runCommand(t, dir, id, "logout")
+			if _, err := tryCommand(dir, id, "login -user=root -pass=pwned -id={id}"); err == nil {
+				t.Errorf("root logged in with the injected password")
+			}
+		})
+	}
+}
@@ -13,13 +13,14 @@ import (
 type MkFs struct {
 	Id   string
 	Type string
+	Fs   string
 }
 
 func ParserMkFs(tokens []string) (string, error) {
 	cmd := &MkFs{}
 
 	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`(?i)-id(?-i)=\S+|(?i)-type(?-i)=\S+`)
+	re := regexp.MustCompile(`(?i)-id(?-i)=\S+|(?i)-type(?-i)=\S+|(?i)-fs(?-i)=\S+`)
 	matches := re.FindAllString(args, -1)
 
 	for _, match := range matches {
@@ -39,6 +40,12 @@ func ParserMkFs(tokens []string) (string, error) {
 				return "", fmt.Errorf("invalid type: %s", value)
 			}
 			cmd.Type = value
+		case "-fs":
+			value = strings.ToLower(value)
+			if value != "2fs" && value != "3fs" {
+				return "", fmt.Errorf("invalid file system: %s", value)
+			}
+			cmd.Fs = value
 		}
 	}
 
@@ -50,6 +57,10 @@ func ParserMkFs(tokens []string) (string, error) {
 		cmd.Type = "full"
 	}
 
+	if cmd.Fs == "" {
+		cmd.Fs = "2fs"
+	}
+
 	if err := cmd.commandMkFs(); err != nil {
 		return "", err
 	}
@@ -63,18 +74,19 @@ func (cmd *MkFs) commandMkFs() error {
 		return err
 	}
 
-	// TODO verify if the partition is mounted
-	fmt.Println("MOUNTED PARTITION: ")
-	mountedPartition.Print()
+	filesystemType := int32(2)
+	if cmd.Fs == "3fs" {
+		filesystemType = 3
+	}
 
-	n := mountedPartition.CalculateN()
-	fmt.Println("N: ", n)
+	n := mountedPartition.CalculateN(filesystemType)
 
 	superBlock := structures.SuperBlock{}
-	superBlock.CreateSuperBlock(mountedPartition.PartStart, n)
+	superBlock.CreateSuperBlock(mountedPartition.Start, n, filesystemType)
 
-	fmt.Println("SUPER BLOCK: ")
-	superBlock.Print()
+	if err := superBlock.CreateJournal(partitionPath); err != nil {
+		return err
+	}
 
 	if err := superBlock.CreateBitMaps(partitionPath); err != nil {
 		return err
@@ -84,9 +96,7 @@ func (cmd *MkFs) commandMkFs() error {
 		return err
 	}
 
-	superBlock.Print()
-
-	if err := superBlock.WriteSuperBlock(partitionPath, int64(mountedPartition.PartStart), int64(mountedPartition.PartStart+int32(binary.Size(superBlock)))); err != nil {
+	if err := superBlock.WriteSuperBlock(partitionPath, int64(mountedPartition.Start), int64(mountedPartition.Start+int32(binary.Size(superBlock)))); err != nil {
 		return err
 	}
 
@@ -94,5 +104,5 @@ func (cmd *MkFs) commandMkFs() error {
 }
 
 func (cmd *MkFs) Print() string {
-	return fmt.Sprintf("File system created successfully in partition %s", cmd.Id)
+	return fmt.Sprintf("File system %s created successfully in partition %s", cmd.Fs, cmd.Id)
 }
@@ -62,7 +62,11 @@ func (cmd *MkGRP) commandMkGRP() error {
 	}
 
 	sb := &structures.SuperBlock{}
-	if err := sb.ReadSuperBlock(partitionPath, int64(mountedPartition.PartStart)); err != nil {
+	if err := sb.ReadSuperBlock(partitionPath, int64(mountedPartition.Start)); err != nil {
+		return err
+	}
+
+	if err := sb.CheckJournalSpace(partitionPath, "/users.txt", cmd.Name); err != nil {
 		return err
 	}
 
@@ -80,7 +84,11 @@ func (cmd *MkGRP) commandMkGRP() error {
 		return err
 	}
 
-	if err := sb.WriteSuperBlock(partitionPath, int64(mountedPartition.PartStart), int64(mountedPartition.PartStart+int32(binary.Size(sb)))); err != nil {
+	if err := sb.WriteSuperBlock(partitionPath, int64(mountedPartition.Start), int64(mountedPartition.Start+int32(binary.Size(sb)))); err != nil {
+		return err
+	}
+
+	if err := sb.AddJournal(partitionPath, "mkgrp", "/users.txt", cmd.Name); err != nil {
 		return err
 	}
 
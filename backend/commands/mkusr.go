@@ -86,7 +86,7 @@ func (cmd *MkUSR) commandMkUSR() error {
 	}
 
 	sb := &structures.SuperBlock{}
-	if err := sb.ReadSuperBlock(partitionPath, int64(mountedPartition.PartStart)); err != nil {
+	if err := sb.ReadSuperBlock(partitionPath, int64(mountedPartition.Start)); err != nil {
 		return err
 	}
 
@@ -96,7 +96,16 @@ func (cmd *MkUSR) commandMkUSR() error {
 
 	global.ParserUserData(texto)
 
-	if err := global.AddUserToGroup(cmd.User, cmd.Pass, cmd.Grp); err != nil {
+	hash, err := global.HashPassword(cmd.Pass)
+	if err != nil {
+		return err
+	}
+
+	if err := sb.CheckJournalSpace(partitionPath, "/users.txt", cmd.User+","+hash+","+cmd.Grp); err != nil {
+		return err
+	}
+
+	if err := global.AddUserToGroup(cmd.User, hash, cmd.Grp); err != nil {
 		return err
 	}
 
@@ -104,7 +113,11 @@ func (cmd *MkUSR) commandMkUSR() error {
 		return err
 	}
 
-	if err := sb.WriteSuperBlock(partitionPath, int64(mountedPartition.PartStart), int64(mountedPartition.PartStart+int32(binary.Size(sb)))); err != nil {
+	if err := sb.WriteSuperBlock(partitionPath, int64(mountedPartition.Start), int64(mountedPartition.Start+int32(binary.Size(sb)))); err != nil {
+		return err
+	}
+
+	if err := sb.AddJournal(partitionPath, "mkusr", "/users.txt", cmd.User+","+hash+","+cmd.Grp); err != nil {
 		return err
 	}
 
@@ -72,7 +72,7 @@ func (cmd *Mount) commandMount() (string, error) {
 	partition, indexPartition := mbr.GetPartitionByName(cmd.Name)
 
 	if partition == nil {
-		return "", fmt.Errorf("partition not found")
+		return cmd.mountLogicalPartition(&mbr)
 	}
 
 	if partition.PartType != 'P' {
@@ -85,7 +85,12 @@ func (cmd *Mount) commandMount() (string, error) {
 		return "", err
 	}
 
+	if _, exists := global.MountedPartitions[idPartition]; exists {
+		return "", fmt.Errorf("partition already mounted with id: %s", idPartition)
+	}
+
 	global.MountedPartitions[idPartition] = cmd.Path
+	global.MountedNames[idPartition] = cmd.Name
 
 	if err := partition.MountPartition(indexPartition, idPartition); err != nil {
 		return "", err
@@ -100,6 +105,45 @@ func (cmd *Mount) commandMount() (string, error) {
 	return fmt.Sprintf("partition mounted successfully with id: %s", idPartition), nil
 }
 
+func (cmd *Mount) mountLogicalPartition(mbr *structures.MBR) (string, error) {
+	extended := mbr.GetExtendedPartition()
+	if extended == nil {
+		return "", fmt.Errorf("partition not found")
+	}
+
+	node, err := structures.FindLogicalPartition(cmd.Path, extended, cmd.Name)
+	if err != nil {
+		return "", fmt.Errorf("partition not found")
+	}
+
+	if global.IsPartitionMounted(cmd.Path, cmd.Name) {
+		return "", fmt.Errorf("partition already mounted")
+	}
+
+	// Logical partitions are numbered after the four primary slots
+	var idPartition string
+	for index := 4; ; index++ {
+		idPartition, err = cmd.GenerateIdPartition(index)
+		if err != nil {
+			return "", err
+		}
+		if _, exists := global.MountedPartitions[idPartition]; !exists {
+			break
+		}
+	}
+
+	node.EBR.MountPartition()
+
+	if err := node.EBR.WriteEBR(cmd.Path, node.Offset, int64(extended.PartStart+extended.PartSize)); err != nil {
+		return "", err
+	}
+
+	global.MountedPartitions[idPartition] = cmd.Path
+	global.MountedNames[idPartition] = cmd.Name
+
+	return fmt.Sprintf("logical partition mounted successfully with id: %s", idPartition), nil
+}
+
 func (cmd *Mount) GenerateIdPartition(indexPartition int) (string, error) {
 	letter, err := utils.GetLetter(cmd.Path)
 	if err != nil {
@@ -0,0 +1,151 @@
+package commands
+
+import (
+	"backend/global"
+	"backend/structures"
+	"backend/utils"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type Move struct {
+	Path    string
+	Destino string
+}
+
+func ParserMove(tokens []string) (string, error) {
+	cmd := &Move{}
+
+	args := strings.Join(tokens, " ")
+	re := regexp.MustCompile(`(?i)-path(?-i)="[^"]+"|(?i)-path(?-i)=\S+|(?i)-destino(?-i)="[^"]+"|(?i)-destino(?-i)=\S+`)
+	matches := re.FindAllString(args, -1)
+
+	for _, match := range matches {
+		key, value, err := utils.ParseToken(match)
+		if err != nil {
+			return "", err
+		}
+
+		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
+			value = strings.Trim(value, "\"")
+		}
+
+		switch key {
+		case "-path":
+			if value == "" {
+				return "", fmt.Errorf("invalid path: %s", value)
+			}
+			cmd.Path = value
+		case "-destino":
+			if value == "" {
+				return "", fmt.Errorf("invalid destination: %s", value)
+			}
+			cmd.Destino = value
+		}
+	}
+
+	if cmd.Path == "" {
+		return "", fmt.Errorf("path is required")
+	}
+
+	if cmd.Destino == "" {
+		return "", fmt.Errorf("destino is required")
+	}
+
+	if err := cmd.commandMove(); err != nil {
+		return "", err
+	}
+
+	return cmd.Print(), nil
+}
+
+func (cmd *Move) commandMove() error {
+	if !global.IsUserLogged() {
+		return fmt.Errorf("you must be logged in")
+	}
+
+	mountedPartition, partitionPath, err := global.GetMountedPartition(global.LoggedPartition)
+	if err != nil {
+		return err
+	}
+
+	sb := &structures.SuperBlock{}
+	if err := sb.ReadSuperBlock(partitionPath, int64(mountedPartition.Start)); err != nil {
+		return err
+	}
+
+	if err := sb.CheckJournalSpace(partitionPath, cmd.Path, cmd.Destino); err != nil {
+		return err
+	}
+
+	global.ParserUserData(sb.GetFile(partitionPath, 0, []string{"users.txt"}))
+
+	uid, gid, err := global.GetLoggedUserIDs()
+	if err != nil {
+		return err
+	}
+
+	if err := movePath(sb, partitionPath, splitPath(cmd.Path), splitPath(cmd.Destino), uid, gid); err != nil {
+		return err
+	}
+
+	if err := sb.WriteSuperBlock(partitionPath, int64(mountedPartition.Start), int64(mountedPartition.Start+int32(binary.Size(sb)))); err != nil {
+		return err
+	}
+
+	if err := sb.AddJournal(partitionPath, "move", cmd.Path, cmd.Destino); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// movePath moves the file or folder source inside the folder destination
+func movePath(sb *structures.SuperBlock, partitionPath string, source, destination []string, uid, gid int32) error {
+	if err := checkProtectedPath(source, "moved"); err != nil {
+		return err
+	}
+
+	sourceName := "/" + strings.Join(source, "/")
+	destinationName := "/" + strings.Join(destination, "/")
+
+	if _, err := sb.CheckAccess(partitionPath, source, uid, gid, 0); err != nil {
+		return err
+	}
+
+	parentIndex, err := sb.CheckAccess(partitionPath, source[:len(source)-1], uid, gid, structures.PermissionWrite)
+	if err != nil {
+		return err
+	}
+
+	destinationIndex, err := sb.CheckAccess(partitionPath, destination, uid, gid, structures.PermissionWrite)
+	if err != nil {
+		return err
+	}
+
+	if isSubPath(source, destination) {
+		return fmt.Errorf("%s cannot be moved inside itself", sourceName)
+	}
+
+	folder := &structures.Inode{}
+	if err := folder.ReadInode(partitionPath, int64(sb.SInodeStart+destinationIndex*sb.SInodeSize)); err != nil {
+		return err
+	}
+
+	if folder.IType != '0' {
+		return fmt.Errorf("destination %s is not a folder", destinationName)
+	}
+
+	name := source[len(source)-1]
+	if sb.GetInodeIndex(partitionPath, append(append([]string{}, destination...), name)) != -1 {
+		return fmt.Errorf("%s already exists in %s", name, destinationName)
+	}
+
+	return sb.MoveEntry(partitionPath, parentIndex, name, destinationIndex)
+}
+
+func (cmd *Move) Print() string {
+	return fmt.Sprintf("%s moved to %s", cmd.Path, cmd.Destino)
+}
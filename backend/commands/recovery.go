@@ -0,0 +1,250 @@
+package commands
+
+import (
+	"backend/global"
+	"backend/structures"
+	"backend/utils"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type Recovery struct {
+	Id       string
+	replayed int
+}
+
+func ParserRecovery(tokens []string) (string, error) {
+	cmd := &Recovery{}
+
+	args := strings.Join(tokens, " ")
+	re := regexp.MustCompile(`(?i)-id(?-i)="[^"]+"|(?i)-id(?-i)=\S+`)
+	matches := re.FindAllString(args, -1)
+
+	for _, match := range matches {
+		key, value, err := utils.ParseToken(match)
+		if err != nil {
+			return "", err
+		}
+
+		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
+			value = strings.Trim(value, "\"")
+		}
+
+		switch key {
+		case "-id":
+			if value == "" {
+				return "", fmt.Errorf("invalid id: %s", value)
+			}
+			cmd.Id = value
+		}
+	}
+
+	if cmd.Id == "" {
+		return "", fmt.Errorf("missing id")
+	}
+
+	if err := cmd.commandRecovery(); err != nil {
+		return "", err
+	}
+
+	return cmd.Print(), nil
+}
+
+func (cmd *Recovery) commandRecovery() error {
+	partition, path, err := global.GetMountedPartition(cmd.Id)
+	if err != nil {
+		return err
+	}
+
+	sb := &structures.SuperBlock{}
+	if err := sb.ReadSuperBlock(path, int64(partition.Start)); err != nil {
+		return err
+	}
+
+	if !sb.IsFormatted() {
+		return fmt.Errorf("partition %s is not formatted", cmd.Id)
+	}
+
+	entries, err := sb.GetJournalEntries(path)
+	if err != nil {
+		return err
+	}
+
+	// Rebuild an empty filesystem with the same layout, the journal is left untouched
+	mountCount := sb.SMntCount
+	sb.CreateSuperBlock(partition.Start, sb.InodesTotal(), sb.SFilesystemType)
+	sb.SMntCount = mountCount
+
+	if err := sb.CreateBitMaps(path); err != nil {
+		return err
+	}
+
+	if err := sb.CreateUserFile(path); err != nil {
+		return err
+	}
+
+	session := newReplaySession()
+	for _, entry := range entries {
+		if err := replayJournalEntry(sb, path, entry, session); err != nil {
+			return fmt.Errorf("failed to replay operation %d (%s %s): %v", entry.Count, entry.Operation, entry.Path, err)
+		}
+		cmd.replayed++
+	}
+
+	return sb.WriteSuperBlock(path, int64(partition.Start), int64(partition.Start+int32(binary.Size(sb))))
+}
+
+// replaySession follows the journaled logins and umasks, so the inodes created again get
+// the same owner and permissions
+type replaySession struct {
+	uid   int32
+	gid   int32
+	umask [3]byte
+}
+
+func newReplaySession() *replaySession {
+	return &replaySession{uid: structures.RootUserID, gid: structures.RootUserID, umask: structures.DefaultUmask}
+}
+
+// journaledSession returns the user and umask recovery would replay the next operation with
+func journaledSession(sb *structures.SuperBlock, path string) (string, [3]byte, error) {
+	user, umask := "root", structures.DefaultUmask
+	if !sb.HasJournal() {
+		return user, umask, nil
+	}
+
+	entries, err := sb.GetJournalEntries(path)
+	if err != nil {
+		return "", umask, err
+	}
+
+	for _, entry := range entries {
+		switch entry.Operation {
+		case "login":
+			user, umask = entry.Content, structures.DefaultUmask
+		case "umask":
+			if value, err := structures.ParsePermission(entry.Content); err == nil {
+				umask = value
+			}
+		}
+	}
+
+	return user, umask, nil
+}
+
+// replayJournalEntry applies an operation of the journal again, without permission checks
+// and without appending it to the journal
+func replayJournalEntry(sb *structures.SuperBlock, path string, entry structures.JournalEntry, session *replaySession) error {
+	filePath := splitPath(entry.Path)
+	uid, gid := session.uid, session.gid
+
+	switch entry.Operation {
+	case "login":
+		global.ParserUserData(sb.GetFile(path, 0, []string{"users.txt"}))
+		uid, gid, err := global.GetUserIDs(entry.Content)
+		if err != nil {
+			return err
+		}
+		*session = replaySession{uid: uid, gid: gid, umask: structures.DefaultUmask}
+		return nil
+	case "umask":
+		umask, err := structures.ParsePermission(entry.Content)
+		if err != nil {
+			return err
+		}
+		session.umask = umask
+		return nil
+	case "mkdir":
+		return sb.CreateNewInode(path, filePath, 0, false, true, uid, gid, session.umask)
+	case "mkfile":
+		content, err := journaledContent(entry.Content)
+		if err != nil {
+			return err
+		}
+		if err := sb.CreateNewInode(path, filePath, 0, true, true, uid, gid, session.umask); err != nil {
+			return err
+		}
+		_, err = sb.WriteFile(path, 0, filePath, content)
+		return err
+	case "edit":
+		_, err := sb.WriteFile(path, 0, filePath, entry.Content)
+		return err
+	case "rename":
+		if err := checkProtectedPath(filePath, "renamed"); err != nil {
+			return err
+		}
+		return renamePath(sb, path, filePath, entry.Content)
+	case "copy":
+		// Copies run as their user again, so the same unreadable entries are skipped
+		_, err := copyPath(sb, path, filePath, splitPath(entry.Content), uid, gid)
+		return err
+	case "move":
+		return movePath(sb, path, filePath, splitPath(entry.Content), uid, gid)
+	case "chmod":
+		args := strings.Split(entry.Content, ",")
+		if len(args) != 2 {
+			return fmt.Errorf("invalid chmod entry: %s", entry.Content)
+		}
+		return chmodPath(sb, path, entry.Path, args[0], args[1] == "r")
+	case "chown":
+		global.ParserUserData(sb.GetFile(path, 0, []string{"users.txt"}))
+		args := strings.Split(entry.Content, ",")
+		if len(args) != 3 {
+			return fmt.Errorf("invalid chown entry: %s", entry.Content)
+		}
+		uid, err := strconv.Atoi(args[2])
+		if err != nil {
+			return err
+		}
+		_, err = chownPath(sb, path, entry.Path, args[0], args[1] == "r", int32(uid))
+		return err
+	case "remove":
+		if err := checkProtectedPath(filePath, "removed"); err != nil {
+			return err
+		}
+		return removePath(sb, path, filePath)
+	case "mkgrp", "rmgrp", "mkusr", "rmusr", "chgrp", "chpass":
+		return replayUsersEntry(sb, path, entry)
+	default:
+		return fmt.Errorf("unknown operation: %s", entry.Operation)
+	}
+}
+
+func replayUsersEntry(sb *structures.SuperBlock, path string, entry structures.JournalEntry) error {
+	array := []string{"users.txt"}
+
+	global.ParserUserData(sb.GetFile(path, 0, array))
+
+	args := strings.Split(entry.Content, ",")
+
+	var err error
+	switch {
+	case entry.Operation == "mkgrp":
+		err = global.AddGroup(entry.Content)
+	case entry.Operation == "rmgrp":
+		err = global.RemoveGroup(entry.Content)
+	case entry.Operation == "rmusr":
+		err = global.RemoveUser(entry.Content)
+	case entry.Operation == "mkusr" && len(args) == 3:
+		err = global.AddUserToGroup(args[0], args[1], args[2])
+	case entry.Operation == "chgrp" && len(args) == 2:
+		err = global.ChangeUserGroup(args[0], args[1])
+	case entry.Operation == "chpass" && len(args) == 2:
+		err = global.SetPassword(args[0], args[1])
+	default:
+		err = fmt.Errorf("invalid content: %s", entry.Content)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = sb.WriteFile(path, 0, array, global.ConvertToString())
+	return err
+}
+
+func (cmd *Recovery) Print() string {
+	return fmt.Sprintf("partition %s recovered, %d operations replayed from the journal", cmd.Id, cmd.replayed)
+}
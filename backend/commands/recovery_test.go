@@ -0,0 +1,258 @@
+package commands
+
+import (
+	"backend/global"
+	"backend/structures"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var testParsers = map[string]func([]string) (string, error){
+	"mkdisk":   ParserMkDisk,
+	"fdisk":    ParserFDisk,
+	"mount":    ParserMount,
+	"unmount":  ParserUnmount,
+	"mkfs":     ParserMkFs,
+	"login":    ParserLogin,
+	"logout":   ParserLogout,
+	"mkgrp":    ParserMkGRP,
+	"rmgrp":    ParserRmGRP,
+	"mkusr":    ParserMkUSR,
+	"rmusr":    ParserRmUSR,
+	"chgrp":    ParserChGRP,
+	"chpass":   ParserChPass,
+	"mkdir":    ParserMkDIR,
+	"mkfile":   ParserMkFile,
+	"edit":     ParserEdit,
+	"rename":   ParserRename,
+	"copy":     ParserCopy,
+	"move":     ParserMove,
+	"remove":   ParserRemove,
+	"umask":    ParserUmask,
+	"chmod":    ParserChmod,
+	"chown":    ParserChown,
+	"loss":     ParserLoss,
+	"recovery": ParserRecovery,
+}
+
+// tryCommand runs a command line, {dir} and {id} are replaced by the test folder and the mounted partition
+func tryCommand(dir, id, line string) (string, error) {
+	line = strings.NewReplacer("{dir}", dir, "{id}", id).Replace(line)
+	tokens := strings.Fields(line)
+
+	return testParsers[tokens[0]](tokens[1:])
+}
+
+// runCommand runs a command line that must succeed
+func runCommand(t *testing.T, dir, id, line string) string {
+	t.Helper()
+
+	result, err := tryCommand(dir, id, line)
+	if err != nil {
+		t.Fatalf("%s: %v", line, err)
+	}
+
+	return result
+}
+
+// newTestPartition creates a disk with an ext3 partition, mounts and formats it, and logs root in
+func newTestPartition(t *testing.T) (string, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	runCommand(t, dir, "", "mkdisk -size=1 -unit=M -path={dir}/disk.mia")
+	runCommand(t, dir, "", "fdisk -type=P -unit=k -name=P1 -size=300 -path={dir}/disk.mia")
+
+	mounted := runCommand(t, dir, "", "mount -path={dir}/disk.mia -name=P1")
+	id := mounted[strings.LastIndex(mounted, " ")+1:]
+	t.Cleanup(func() {
+		if _, err := global.LogUserOut(); err != nil {
+			global.ClearData()
+		}
+		global.RemoveMountedPartition(id)
+	})
+
+	runCommand(t, dir, id, "mkfs -id={id} -fs=3fs")
+	runCommand(t, dir, id, "login -user=root -pass=123 -id={id}")
+
+	return dir, id
+}
+
+// snapshot lists every path of the partition with its type, permissions, owner, group and content
+func snapshot(t *testing.T, id string) string {
+	t.Helper()
+
+	mountedPartition, partitionPath, err := global.GetMountedPartition(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sb := &structures.SuperBlock{}
+	if err := sb.ReadSuperBlock(partitionPath, int64(mountedPartition.Start)); err != nil {
+		t.Fatal(err)
+	}
+
+	var lines []string
+	var walk func(index int32, filePath []string)
+	walk = func(index int32, filePath []string) {
+		inode := &structures.Inode{}
+		if err := inode.ReadInode(partitionPath, int64(sb.SInodeStart+index*sb.SInodeSize)); err != nil {
+			t.Fatal(err)
+		}
+
+		line := fmt.Sprintf("/%s %c %s %d %d", strings.Join(filePath, "/"), inode.IType, inode.GetPermissionString(), inode.IuId, inode.IGid)
+		if inode.IType == '1' {
+			lines = append(lines, line+" "+sb.GetFile(partitionPath, 0, filePath))
+			return
+		}
+		lines = append(lines, line)
+
+		for _, entry := range sb.GetFolderEntries(partitionPath, inode) {
+			name := strings.TrimRight(string(entry.BName[:]), "\x00")
+			walk(entry.BInode, append(append([]string{}, filePath...), name))
+		}
+	}
+	walk(0, nil)
+
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+func TestRecovery(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands []string
+	}{
+		{
+			name: "files and folders",
+			commands: []string{
+				"mkdir -p -path=/home/docs",
+				"mkfile -path=/home/docs/a.txt -size=100",
+				"mkfile -r -path=/tmp/b/c.txt -cont={dir}/content.txt",
+				"edit -path=/home/docs/a.txt -contenido={dir}/content.txt",
+				"rename -path=/home/docs/a.txt -name=d.txt",
+				"copy -path=/home/docs -destino=/tmp",
+				"move -path=/tmp/b -destino=/home",
+				"remove -path=/tmp/docs/d.txt",
+			},
+		},
+		{
+			name: "users and groups",
+			commands: []string{
+				"mkgrp -name=dev",
+				"mkgrp -name=ops",
+				"mkusr -user=ana -pass=1 -grp=dev",
+				"mkusr -user=luis -pass=2 -grp=dev",
+				"rmusr -user=luis",
+				"rmgrp -name=ops",
+				"chgrp -user=ana -grp=root",
+				"chpass -user=ana -new=3",
+			},
+		},
+		{
+			name: "permissions and sessions",
+			commands: []string{
+				"mkgrp -name=dev",
+				"mkusr -user=ana -pass=1 -grp=dev",
+				"mkdir -path=/shared",
+				"chmod -path=/shared -ugo=777",
+				"umask -value=077",
+				"mkdir -path=/private",
+				"logout",
+				"login -user=ana -pass=1 -id={id}",
+				"mkdir -path=/shared/ana",
+				"mkfile -path=/shared/ana/notes.txt -size=10",
+				"mkdir -path=/shared/other",
+				"move -path=/shared/ana/notes.txt -destino=/shared/other",
+				"copy -path=/shared/other -destino=/shared/ana",
+				"logout",
+				"login -user=root -pass=123 -id={id}",
+				"chown -path=/shared/ana -usuario=root -r",
+				"chmod -path=/shared -ugo=755 -r",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, id := newTestPartition(t)
+			if err := os.WriteFile(filepath.Join(dir, "content.txt"), []byte(strings.Repeat("journal ", 20)), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, line := range tt.commands {
+				runCommand(t, dir, id, line)
+			}
+			want := snapshot(t, id)
+
+			runCommand(t, dir, id, "loss -id={id}")
+			runCommand(t, dir, id, "recovery -id={id}")
+
+			if got := snapshot(t, id); got != want {
+				t.Errorf("recovered partition differs\ngot:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestJournalCheckpoint(t *testing.T) {
+	dir, id := newTestPartition(t)
+	// Every edit takes 32 records, 40 of them are more than the journal of a 300K partition holds
+	if err := os.WriteFile(filepath.Join(dir, "content.txt"), []byte(strings.Repeat("checkpoint", 200)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{
+		"mkgrp -name=dev",
+		"mkusr -user=ana -pass=1 -grp=dev",
+		"mkdir -path=/shared",
+		"chmod -path=/shared -ugo=777",
+		"logout",
+		"login -user=ana -pass=1 -id={id}",
+		"umask -value=027",
+		"mkdir -path=/shared/ana",
+		"mkfile -path=/shared/ana/log.txt -size=10",
+	} {
+		runCommand(t, dir, id, line)
+	}
+	for i := 0; i < 40; i++ {
+		runCommand(t, dir, id, "edit -path=/shared/ana/log.txt -contenido={dir}/content.txt")
+	}
+	runCommand(t, dir, id, "mkdir -path=/shared/ana/new")
+	runCommand(t, dir, id, "mkfile -path=/shared/ana/new/big.txt -size=60000")
+
+	entries, err := readJournalEntries(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries[0].Operation != "edit" || entries[0].Path != "/users.txt" {
+		t.Fatalf("journal starts with %s %s, want the users.txt of the checkpoint", entries[0].Operation, entries[0].Path)
+	}
+
+	// Recovery only rebuilds users.txt and what was created after the checkpoint
+	recovered := func() []string {
+		var lines []string
+		for _, line := range strings.Split(snapshot(t, id), "\n") {
+			if strings.HasPrefix(line, "/shared/ana/new") || strings.HasPrefix(line, "/users.txt") {
+				lines = append(lines, line)
+			}
+		}
+		return lines
+	}
+	want := recovered()
+
+	runCommand(t, dir, id, "loss -id={id}")
+	runCommand(t, dir, id, "recovery -id={id}")
+
+	if got := recovered(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("recovered partition differs\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	runCommand(t, dir, id, "logout")
+	runCommand(t, dir, id, "login -user=ana -pass=1 -id={id}")
+	runCommand(t, dir, id, "mkfile -path=/shared/ana/new/after.txt -size=10")
+}
@@ -0,0 +1,151 @@
+package commands
+
+import (
+	"backend/global"
+	"backend/structures"
+	"backend/utils"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type Remove struct {
+	Path string
+}
+
+func ParserRemove(tokens []string) (string, error) {
+	cmd := &Remove{}
+
+	args := strings.Join(tokens, " ")
+	re := regexp.MustCompile(`(?i)-path(?-i)="[^"]+"|(?i)-path(?-i)=\S+`)
+	matches := re.FindAllString(args, -1)
+
+	for _, match := range matches {
+		key, value, err := utils.ParseToken(match)
+		if err != nil {
+			return "", err
+		}
+
+		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
+			value = strings.Trim(value, "\"")
+		}
+
+		switch key {
+		case "-path":
+			if value == "" {
+				return "", fmt.Errorf("invalid path: %s", value)
+			}
+			cmd.Path = value
+		}
+	}
+
+	if cmd.Path == "" {
+		return "", fmt.Errorf("path is required")
+	}
+
+	if err := cmd.commandRemove(); err != nil {
+		return "", err
+	}
+
+	return cmd.Print(), nil
+}
+
+func (cmd *Remove) commandRemove() error {
+	if !global.IsUserLogged() {
+		return fmt.Errorf("you must be logged in")
+	}
+
+	mountedPartition, partitionPath, err := global.GetMountedPartition(global.LoggedPartition)
+	if err != nil {
+		return err
+	}
+
+	sb := &structures.SuperBlock{}
+	if err := sb.ReadSuperBlock(partitionPath, int64(mountedPartition.Start)); err != nil {
+		return err
+	}
+
+	if err := sb.CheckJournalSpace(partitionPath, cmd.Path, ""); err != nil {
+		return err
+	}
+
+	filePath := splitPath(cmd.Path)
+	if err := checkProtectedPath(filePath, "removed"); err != nil {
+		return err
+	}
+
+	global.ParserUserData(sb.GetFile(partitionPath, 0, []string{"users.txt"}))
+
+	uid, gid, err := global.GetLoggedUserIDs()
+	if err != nil {
+		return err
+	}
+
+	index, err := sb.CheckAccess(partitionPath, filePath, uid, gid, structures.PermissionWrite)
+	if err != nil {
+		return err
+	}
+
+	if _, err := sb.CheckAccess(partitionPath, filePath[:len(filePath)-1], uid, gid, structures.PermissionWrite|structures.PermissionExecute); err != nil {
+		return err
+	}
+
+	denied, err := sb.FindInodeWithoutPermission(partitionPath, index, cmd.Path, uid, gid, structures.PermissionWrite)
+	if err != nil {
+		return err
+	}
+	if denied != "" {
+		return fmt.Errorf("permission denied: %s", denied)
+	}
+
+	if err := removePath(sb, partitionPath, filePath); err != nil {
+		return err
+	}
+
+	if err := sb.WriteSuperBlock(partitionPath, int64(mountedPartition.Start), int64(mountedPartition.Start+int32(binary.Size(sb)))); err != nil {
+		return err
+	}
+
+	if err := sb.AddJournal(partitionPath, "remove", cmd.Path, ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// checkProtectedPath refuses to touch the root folder and users.txt, action is used in the error
+func checkProtectedPath(filePath []string, action string) error {
+	if len(filePath) == 0 {
+		return fmt.Errorf("the root folder cannot be %s", action)
+	}
+
+	if len(filePath) == 1 && filePath[0] == "users.txt" {
+		return fmt.Errorf("users.txt cannot be %s", action)
+	}
+
+	return nil
+}
+
+func removePath(sb *structures.SuperBlock, partitionPath string, filePath []string) error {
+	parentIndex := sb.GetInodeIndex(partitionPath, filePath[:len(filePath)-1])
+	if parentIndex == -1 {
+		return fmt.Errorf("path not found: /%s", strings.Join(filePath, "/"))
+	}
+
+	return sb.RemoveInode(partitionPath, parentIndex, filePath[len(filePath)-1])
+}
+
+func splitPath(path string) []string {
+	var result []string
+	for _, part := range strings.Split(path, "/") {
+		if part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
+
+func (cmd *Remove) Print() string {
+	return fmt.Sprintf("%s removed successfully", cmd.Path)
+}
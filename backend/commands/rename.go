@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"backend/global"
+	"backend/structures"
+	"backend/utils"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type Rename struct {
+	Path string
+	Name string
+}
+
+func ParserRename(tokens []string) (string, error) {
+	cmd := &Rename{}
+
+	args := strings.Join(tokens, " ")
+	re := regexp.MustCompile(`(?i)-path(?-i)="[^"]+"|(?i)-path(?-i)=\S+|(?i)-name(?-i)="[^"]+"|(?i)-name(?-i)=\S+`)
+	matches := re.FindAllString(args, -1)
+
+	for _, match := range matches {
+		key, value, err := utils.ParseToken(match)
+		if err != nil {
+			return "", err
+		}
+
+		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
+			value = strings.Trim(value, "\"")
+		}
+
+		switch key {
+		case "-path":
+			if value == "" {
+				return "", fmt.Errorf("invalid path: %s", value)
+			}
+			cmd.Path = value
+		case "-name":
+			if value == "" {
+				return "", fmt.Errorf("invalid name: %s", value)
+			}
+			cmd.Name = value
+		}
+	}
+
+	if cmd.Path == "" {
+		return "", fmt.Errorf("path is required")
+	}
+
+	if cmd.Name == "" {
+		return "", fmt.Errorf("name is required")
+	}
+
+	if err := cmd.commandRename(); err != nil {
+		return "", err
+	}
+
+	return cmd.Print(), nil
+}
+
+func (cmd *Rename) commandRename() error {
+	if !global.IsUserLogged() {
+		return fmt.Errorf("you must be logged in")
+	}
+
+	if err := structures.ValidateName(cmd.Name); err != nil {
+		return err
+	}
+
+	mountedPartition, partitionPath, err := global.GetMountedPartition(global.LoggedPartition)
+	if err != nil {
+		return err
+	}
+
+	sb := &structures.SuperBlock{}
+	if err := sb.ReadSuperBlock(partitionPath, int64(mountedPartition.Start)); err != nil {
+		return err
+	}
+
+	if err := sb.CheckJournalSpace(partitionPath, cmd.Path, cmd.Name); err != nil {
+		return err
+	}
+
+	filePath := splitPath(cmd.Path)
+	if err := checkProtectedPath(filePath, "renamed"); err != nil {
+		return err
+	}
+
+	global.ParserUserData(sb.GetFile(partitionPath, 0, []string{"users.txt"}))
+
+	uid, gid, err := global.GetLoggedUserIDs()
+	if err != nil {
+		return err
+	}
+
+	if _, err := sb.CheckAccess(partitionPath, filePath, uid, gid, structures.PermissionWrite); err != nil {
+		return err
+	}
+
+	if _, err := sb.CheckAccess(partitionPath, filePath[:len(filePath)-1], uid, gid, structures.PermissionWrite); err != nil {
+		return err
+	}
+
+	if err := renamePath(sb, partitionPath, filePath, cmd.Name); err != nil {
+		return err
+	}
+
+	if err := sb.WriteSuperBlock(partitionPath, int64(mountedPartition.Start), int64(mountedPartition.Start+int32(binary.Size(sb)))); err != nil {
+		return err
+	}
+
+	if err := sb.AddJournal(partitionPath, "rename", cmd.Path, cmd.Name); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func renamePath(sb *structures.SuperBlock, partitionPath string, filePath []string, name string) error {
+	parentIndex := sb.GetInodeIndex(partitionPath, filePath[:len(filePath)-1])
+	if parentIndex == -1 {
+		return fmt.Errorf("path not found: /%s", strings.Join(filePath, "/"))
+	}
+
+	return sb.RenameEntry(partitionPath, parentIndex, filePath[len(filePath)-1], name)
+}
+
+func (cmd *Rename) Print() string {
+	return fmt.Sprintf("%s renamed to %s", cmd.Path, cmd.Name)
+}
@@ -6,11 +6,14 @@ import (
 	"backend/utils"
 	"fmt"
 	"github.com/goccy/go-graphviz"
+	"html"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type REP struct {
@@ -113,11 +116,14 @@ func (cmd *REP) commandREP() error {
 	case "file":
 		return cmd.repFile()
 	case "ls":
-		//return cmd.repLS()
+		return cmd.repLS()
+	case "tree":
+		return cmd.repTree()
+	case "journaling":
+		return cmd.repJournaling()
 	default:
 		return fmt.Errorf("invalid name: %s", cmd.Name)
 	}
-	return nil
 }
 
 func (cmd *REP) repMBR() error {
@@ -254,7 +260,7 @@ func (cmd *REP) repSB() error {
 	}
 
 	superBlock := &structures.SuperBlock{}
-	if err := superBlock.ReadSuperBlock(path, int64(partition.PartStart)); err != nil {
+	if err := superBlock.ReadSuperBlock(path, int64(partition.Start)); err != nil {
 		return err
 	}
 
@@ -282,7 +288,7 @@ func (cmd *REP) repInode() error {
 	}
 
 	superBlock := &structures.SuperBlock{}
-	if err := superBlock.ReadSuperBlock(path, int64(partition.PartStart)); err != nil {
+	if err := superBlock.ReadSuperBlock(path, int64(partition.Start)); err != nil {
 		return err
 	}
 
@@ -293,15 +299,20 @@ func (cmd *REP) repInode() error {
 	sb.WriteString("\tnode [shape=plaintext];\n")
 	sb.WriteString("\trankdir=LR;\n")
 
+	used, err := superBlock.GetUsedInodes(path)
+	if err != nil {
+		return err
+	}
+
 	inode := &structures.Inode{}
-	for i := int32(0); i < superBlock.SInodesCount; i++ {
+	for j, i := range used {
 		if err := inode.ReadInode(path, int64(superBlock.SInodeStart+(i*superBlock.SInodeSize))); err != nil {
 			return err
 		}
 		sb.WriteString(inode.GetStringBuilder(fmt.Sprintf("Inodo_%d", i)))
 
-		if i < superBlock.SInodesCount-1 {
-			sb.WriteString(fmt.Sprintf("Inodo_%d -> Inodo_%d\n", i, i+1))
+		if j < len(used)-1 {
+			sb.WriteString(fmt.Sprintf("Inodo_%d -> Inodo_%d\n", i, used[j+1]))
 		}
 	}
 
@@ -316,7 +327,7 @@ func (cmd *REP) repBlock() error {
 	}
 
 	superBlock := &structures.SuperBlock{}
-	if err := superBlock.ReadSuperBlock(path, int64(partition.PartStart)); err != nil {
+	if err := superBlock.ReadSuperBlock(path, int64(partition.Start)); err != nil {
 		return err
 	}
 
@@ -327,8 +338,13 @@ func (cmd *REP) repBlock() error {
 	sb.WriteString("\tnode [shape=plaintext];\n")
 	sb.WriteString("\trankdir=LR;\n")
 
+	used, err := superBlock.GetUsedInodes(path)
+	if err != nil {
+		return err
+	}
+
 	inode := &structures.Inode{}
-	for i := int32(0); i < superBlock.SInodesCount; i++ {
+	for _, i := range used {
 		if err := inode.ReadInode(path, int64(superBlock.SInodeStart+(i*superBlock.SInodeSize))); err != nil {
 			return err
 		}
@@ -496,6 +512,174 @@ func (cmd *REP) repBlock() error {
 	return cmd.generateImage(sb.String())
 }
 
+func (cmd *REP) repTree() error {
+	partition, path, err := global.GetMountedPartition(cmd.Id)
+	if err != nil {
+		return err
+	}
+
+	superBlock := &structures.SuperBlock{}
+	if err := superBlock.ReadSuperBlock(path, int64(partition.Start)); err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("digraph G {\n")
+	sb.WriteString("\tnode [shape=plaintext];\n")
+	sb.WriteString("\trankdir=LR;\n")
+
+	visited := make(map[string]bool)
+	if err := cmd.treeInode(&sb, superBlock, path, 0, visited); err != nil {
+		return err
+	}
+
+	sb.WriteString("}")
+	return cmd.generateImage(sb.String())
+}
+
+// treeInode draws an inode and everything reachable from its blocks
+func (cmd *REP) treeInode(sb *strings.Builder, superBlock *structures.SuperBlock, path string, index int32, visited map[string]bool) error {
+	nodeName := fmt.Sprintf("Inodo_%d", index)
+	if visited[nodeName] {
+		return nil
+	}
+	visited[nodeName] = true
+
+	inode := &structures.Inode{}
+	if err := inode.ReadInode(path, int64(superBlock.SInodeStart+index*superBlock.SInodeSize)); err != nil {
+		return err
+	}
+	sb.WriteString(inode.GetStringBuilder(nodeName))
+
+	for j, blockIndex := range inode.IBlock {
+		if blockIndex == -1 {
+			continue
+		}
+
+		blockName := fmt.Sprintf("Bloque_%d", blockIndex)
+		if blockIndex < 0 || blockIndex >= superBlock.BlocksTotal() {
+			blockName = treeInvalidNode(sb, "Bloque", blockIndex)
+		}
+		sb.WriteString(fmt.Sprintf("%s -> %s [label=\"IBlock %d\"]\n", nodeName, blockName, j))
+
+		if blockIndex < 0 || blockIndex >= superBlock.BlocksTotal() {
+			continue
+		}
+
+		var err error
+		if j < 12 {
+			err = cmd.treeDataBlock(sb, superBlock, path, inode, blockIndex, visited)
+		} else {
+			err = cmd.treePointerBlock(sb, superBlock, path, inode, blockIndex, int32(j-12), visited)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// treePointerBlock draws a pointer block, level 0 points directly to data blocks
+func (cmd *REP) treePointerBlock(sb *strings.Builder, superBlock *structures.SuperBlock, path string, inode *structures.Inode, blockIndex, level int32, visited map[string]bool) error {
+	nodeName := fmt.Sprintf("Bloque_%d", blockIndex)
+	if visited[nodeName] {
+		return nil
+	}
+	visited[nodeName] = true
+
+	block := &structures.PointerBlock{}
+	if err := block.ReadPointerBlock(path, int64(superBlock.SBlockStart+blockIndex*superBlock.SBlockSize)); err != nil {
+		return err
+	}
+	sb.WriteString(block.GetStringBuilder(nodeName))
+
+	for k, pointer := range block.PPointers {
+		if pointer == -1 {
+			continue
+		}
+
+		childName := fmt.Sprintf("Bloque_%d", pointer)
+		if pointer < 0 || pointer >= superBlock.BlocksTotal() {
+			childName = treeInvalidNode(sb, "Bloque", pointer)
+		}
+		sb.WriteString(fmt.Sprintf("%s -> %s [label=\"Pointer %d\"]\n", nodeName, childName, k))
+
+		if pointer < 0 || pointer >= superBlock.BlocksTotal() {
+			continue
+		}
+
+		var err error
+		if level == 0 {
+			err = cmd.treeDataBlock(sb, superBlock, path, inode, pointer, visited)
+		} else {
+			err = cmd.treePointerBlock(sb, superBlock, path, inode, pointer, level-1, visited)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// treeDataBlock draws a folder or file block depending on the inode that owns it
+func (cmd *REP) treeDataBlock(sb *strings.Builder, superBlock *structures.SuperBlock, path string, inode *structures.Inode, blockIndex int32, visited map[string]bool) error {
+	nodeName := fmt.Sprintf("Bloque_%d", blockIndex)
+	if visited[nodeName] {
+		return nil
+	}
+	visited[nodeName] = true
+
+	blockStart := int64(superBlock.SBlockStart + blockIndex*superBlock.SBlockSize)
+
+	if inode.IType != '0' {
+		block := &structures.FileBlock{}
+		if err := block.ReadFileBlock(path, blockStart); err != nil {
+			return err
+		}
+		sb.WriteString(block.GetStringBuilder(nodeName))
+		return nil
+	}
+
+	block := &structures.FolderBlock{}
+	if err := block.ReadFolderBlock(path, blockStart); err != nil {
+		return err
+	}
+	sb.WriteString(block.GetNodeStringBuilder(nodeName))
+
+	for _, content := range block.BContent[2:] {
+		if content.BInode == -1 {
+			continue
+		}
+
+		name := strings.TrimRight(string(content.BName[:]), "\x00")
+		childName := fmt.Sprintf("Inodo_%d", content.BInode)
+		if content.BInode < 0 || content.BInode >= superBlock.InodesTotal() {
+			childName = treeInvalidNode(sb, "Inodo", content.BInode)
+		}
+		sb.WriteString(fmt.Sprintf("%s -> %s [label=\"%s\"]\n", nodeName, childName, escapeDotString(name)))
+
+		if content.BInode < 0 || content.BInode >= superBlock.InodesTotal() {
+			continue
+		}
+
+		if err := cmd.treeInode(sb, superBlock, path, content.BInode, visited); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// treeInvalidNode draws a reference that points outside of the filesystem
+func treeInvalidNode(sb *strings.Builder, kind string, index int32) string {
+	nodeName := fmt.Sprintf("\"Invalido_%s_%d\"", kind, index)
+	sb.WriteString(fmt.Sprintf("    %s [shape=box, style=filled, fillcolor=\"#FF9999\", label=\"%s %d invalido\"];\n", nodeName, kind, index))
+	return nodeName
+}
+
 func (cmd *REP) repBMInode() error {
 	partition, path, err := global.GetMountedPartition(cmd.Id)
 	if err != nil {
@@ -503,7 +687,7 @@ func (cmd *REP) repBMInode() error {
 	}
 
 	superBlock := &structures.SuperBlock{}
-	if err := superBlock.ReadSuperBlock(path, int64(partition.PartStart)); err != nil {
+	if err := superBlock.ReadSuperBlock(path, int64(partition.Start)); err != nil {
 		return err
 	}
 
@@ -522,7 +706,7 @@ func (cmd *REP) repBMBlock() error {
 	}
 
 	superBlock := &structures.SuperBlock{}
-	if err := superBlock.ReadSuperBlock(path, int64(partition.PartStart)); err != nil {
+	if err := superBlock.ReadSuperBlock(path, int64(partition.Start)); err != nil {
 		return err
 	}
 
@@ -541,7 +725,7 @@ func (cmd *REP) repFile() error {
 	}
 
 	superBlock := &structures.SuperBlock{}
-	if err := superBlock.ReadSuperBlock(path, int64(partition.PartStart)); err != nil {
+	if err := superBlock.ReadSuperBlock(path, int64(partition.Start)); err != nil {
 		return err
 	}
 
@@ -555,7 +739,16 @@ func (cmd *REP) repFile() error {
 	fileName := filePath[len(filePath)-1]
 
 	sb := &structures.SuperBlock{}
-	if err := sb.ReadSuperBlock(path, int64(partition.PartStart)); err != nil {
+	if err := sb.ReadSuperBlock(path, int64(partition.Start)); err != nil {
+		return err
+	}
+
+	uid, gid, err := reportUserIDs(sb, path)
+	if err != nil {
+		return err
+	}
+
+	if _, err := sb.CheckAccess(path, filteredFilePath, uid, gid, structures.PermissionRead); err != nil {
 		return err
 	}
 
@@ -572,6 +765,17 @@ func (cmd *REP) repFile() error {
 	}
 }
 
+// reportUserIDs returns the ids the logged user has in the users.txt of the reported partition
+func reportUserIDs(sb *structures.SuperBlock, path string) (int32, int32, error) {
+	if !global.IsUserLogged() {
+		return 0, 0, fmt.Errorf("you must be logged in")
+	}
+
+	global.ParserUserData(sb.GetFile(path, 0, []string{"users.txt"}))
+
+	return global.GetLoggedUserIDs()
+}
+
 func generateDotContent(content string) string {
 	return "digraph G {\n" +
 		"    node [shape=box];\n" +
@@ -584,31 +788,164 @@ func escapeDotString(s string) string {
 }
 
 func (cmd *REP) repLS() error {
-	/*partition, path, err := global.GetMountedPartition(cmd.Id)
+	partition, path, err := global.GetMountedPartition(cmd.Id)
 	if err != nil {
 		return err
 	}
 
 	superBlock := &structures.SuperBlock{}
-	if err := superBlock.ReadSuperBlock(path, int64(partition.PartStart)); err != nil {
+	if err := superBlock.ReadSuperBlock(path, int64(partition.Start)); err != nil {
 		return err
 	}
 
-	filePath := strings.Split(cmd.PathFileLs, "/")
 	var filteredFilePath []string
-	for _, part := range filePath {
+	for _, part := range strings.Split(cmd.PathFileLs, "/") {
 		if part != "" {
 			filteredFilePath = append(filteredFilePath, part)
 		}
 	}
-	fileName := filePath[len(filePath)-1]
 
-	sb := &structures.SuperBlock{}
-	if err := sb.ReadSuperBlock(path, int64(partition.PartStart)); err != nil {
+	uid, gid, err := reportUserIDs(superBlock, path)
+	if err != nil {
+		return err
+	}
+
+	index, err := superBlock.CheckAccess(path, filteredFilePath, uid, gid, structures.PermissionRead)
+	if err != nil {
+		return err
+	}
+
+	inode := &structures.Inode{}
+	if err := inode.ReadInode(path, int64(superBlock.SInodeStart+index*superBlock.SInodeSize)); err != nil {
+		return err
+	}
+
+	var entries []structures.FolderContent
+	if inode.IType == '0' {
+		entries = superBlock.GetFolderEntries(path, inode)
+	} else {
+		entry := structures.FolderContent{BInode: index}
+		copy(entry.BName[:], filteredFilePath[len(filteredFilePath)-1])
+		entries = append(entries, entry)
+	}
+
+	groups, users := global.ParseUsersFile(superBlock.GetFile(path, 0, []string{"users.txt"}))
+
+	headers := []string{"Permisos", "Owner", "Grupo", "Size (Bytes)", "Fecha", "Hora", "Tipo", "Name"}
+	var rows [][]string
+
+	for _, entry := range entries {
+		child := &structures.Inode{}
+		if err := child.ReadInode(path, int64(superBlock.SInodeStart+entry.BInode*superBlock.SInodeSize)); err != nil {
+			return err
+		}
+
+		modified := time.Unix(int64(child.IMTime), 0)
+		kind := "Archivo"
+		if child.IType == '0' {
+			kind = "Carpeta"
+		}
+
+		rows = append(rows, []string{
+			child.GetPermissionString(),
+			global.FindUserName(users, strconv.Itoa(int(child.IuId))),
+			global.FindGroupName(groups, strconv.Itoa(int(child.IGid))),
+			strconv.Itoa(int(child.ISize)),
+			modified.Format("02/01/2006"),
+			modified.Format("15:04"),
+			kind,
+			strings.TrimRight(string(entry.BName[:]), "\x00"),
+		})
+	}
+
+	cmd.Path = strings.Trim(cmd.Path, "\" ")
+	if strings.HasSuffix(cmd.Path, ".txt") {
+		return cmd.generateTxt(generateTextTable(headers, rows))
+	}
+
+	return cmd.generateImage(generateDotTable("ReporteLS", "", headers, rows))
+}
+
+func (cmd *REP) repJournaling() error {
+	entries, err := readJournalEntries(cmd.Id)
+	if err != nil {
 		return err
 	}
-	*/
-	return nil //cmd.generateImage(content)
+
+	rows := journalRows(entries)
+
+	cmd.Path = strings.Trim(cmd.Path, "\" ")
+	if strings.HasSuffix(cmd.Path, ".txt") {
+		return cmd.generateTxt(generateTextTable(journalHeaders, rows))
+	}
+
+	return cmd.generateImage(generateDotTable("ReporteJournaling", "Journaling "+cmd.Id, journalHeaders, rows))
+}
+
+// generateDotTable returns a Graphviz graph with the rows in an HTML table, title goes in a row above the headers
+func generateDotTable(node, title string, headers []string, rows [][]string) string {
+	var sb strings.Builder
+	sb.WriteString("digraph G {\n")
+	sb.WriteString("\tnode [shape=plaintext];\n")
+	sb.WriteString(fmt.Sprintf("\t%s [label=<\n", node))
+	sb.WriteString("\t<TABLE BORDER=\"1\" CELLBORDER=\"1\" CELLSPACING=\"0\" CELLPADDING=\"4\">\n")
+
+	if title != "" {
+		sb.WriteString(fmt.Sprintf("<TR><TD COLSPAN=\"%d\" BGCOLOR=\"%s\"><B>%s</B></TD></TR>\n", len(headers), "#333333", html.EscapeString(title)))
+	}
+
+	sb.WriteString("<TR>")
+	for _, header := range headers {
+		sb.WriteString(fmt.Sprintf("<TD BGCOLOR=\"%s\"><B>%s</B></TD>", "#AAAAAA", header))
+	}
+	sb.WriteString("</TR>\n")
+
+	for i, row := range rows {
+		bgColor := "#FFFFFF"
+		if i%2 == 0 {
+			bgColor = "#DDDDDD"
+		}
+
+		sb.WriteString("<TR>")
+		for _, cell := range row {
+			sb.WriteString(fmt.Sprintf("<TD BGCOLOR=\"%s\">%s</TD>", bgColor, html.EscapeString(cell)))
+		}
+		sb.WriteString("</TR>\n")
+	}
+
+	sb.WriteString("    </TABLE>\n")
+	sb.WriteString("    >];\n")
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+func generateTextTable(headers []string, rows [][]string) string {
+	widths := make([]int, len(headers))
+	for i, header := range headers {
+		widths[i] = len(header)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+
+	var sb strings.Builder
+	writeRow := func(cells []string) {
+		var line strings.Builder
+		for i, cell := range cells {
+			line.WriteString(fmt.Sprintf("%-*s  ", widths[i], cell))
+		}
+		sb.WriteString(strings.TrimRight(line.String(), " ") + "\n")
+	}
+
+	writeRow(headers)
+	for _, row := range rows {
+		writeRow(row)
+	}
+
+	return sb.String()
 }
 
 func (cmd *REP) generateImage(content string) error {
@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"backend/global"
+	"backend/structures"
+	"backend/utils"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type Umask struct {
+	Value string
+}
+
+func ParserUmask(tokens []string) (string, error) {
+	cmd := &Umask{}
+
+	args := strings.Join(tokens, " ")
+	re := regexp.MustCompile(`(?i)-value(?-i)=\S+`)
+	matches := re.FindAllString(args, -1)
+
+	for _, match := range matches {
+		key, value, err := utils.ParseToken(match)
+		if err != nil {
+			return "", err
+		}
+
+		switch key {
+		case "-value":
+			if _, err := structures.ParsePermission(value); err != nil {
+				return "", fmt.Errorf("invalid umask: %s", value)
+			}
+			cmd.Value = value
+		}
+	}
+
+	if cmd.Value == "" {
+		return "", fmt.Errorf("value is required")
+	}
+
+	if err := cmd.commandUmask(); err != nil {
+		return "", err
+	}
+
+	return cmd.Print(), nil
+}
+
+func (cmd *Umask) commandUmask() error {
+	if !global.IsUserLogged() {
+		return fmt.Errorf("you must be logged in")
+	}
+
+	mountedPartition, partitionPath, err := global.GetMountedPartition(global.LoggedPartition)
+	if err != nil {
+		return err
+	}
+
+	sb := &structures.SuperBlock{}
+	if err := sb.ReadSuperBlock(partitionPath, int64(mountedPartition.Start)); err != nil {
+		return err
+	}
+
+	umask, err := structures.ParsePermission(cmd.Value)
+	if err != nil {
+		return err
+	}
+
+	// Recovery needs it to give the inodes created again the same permissions
+	if err := sb.AddJournal(partitionPath, "umask", "/", cmd.Value); err != nil {
+		return err
+	}
+
+	global.Umask = umask
+
+	return nil
+}
+
+func (cmd *Umask) Print() string {
+	folder := structures.NewPermission(false, global.Umask)
+	file := structures.NewPermission(true, global.Umask)
+	return fmt.Sprintf("umask set to %s, new folders get %s and new files get %s", cmd.Value, string(folder[:]), string(file[:]))
+}
@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"backend/global"
+	"backend/structures"
+	"backend/utils"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type Unmount struct {
+	Id string
+}
+
+func ParserUnmount(tokens []string) (string, error) {
+	cmd := &Unmount{}
+
+	args := strings.Join(tokens, " ")
+	re := regexp.MustCompile(`(?i)-id(?-i)="[^"]+"|(?i)-id(?-i)=\S+`)
+	matches := re.FindAllString(args, -1)
+
+	for _, match := range matches {
+		key, value, err := utils.ParseToken(match)
+		if err != nil {
+			return "", err
+		}
+
+		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
+			value = strings.Trim(value, "\"")
+		}
+
+		switch key {
+		case "-id":
+			if value == "" {
+				return "", fmt.Errorf("invalid id: %s", value)
+			}
+			cmd.Id = value
+		}
+	}
+
+	if cmd.Id == "" {
+		return "", fmt.Errorf("missing id")
+	}
+
+	if err := cmd.commandUnmount(); err != nil {
+		return "", err
+	}
+
+	return cmd.Print(), nil
+}
+
+func (cmd *Unmount) commandUnmount() error {
+	if global.IsUserLogged() && global.LoggedPartition == cmd.Id {
+		return fmt.Errorf("a user is logged in partition %s, logout first", cmd.Id)
+	}
+
+	partition, path, err := global.GetMountedPartition(cmd.Id)
+	if err != nil {
+		return err
+	}
+
+	sb := &structures.SuperBlock{}
+	if err := sb.ReadSuperBlock(path, int64(partition.Start)); err != nil {
+		return err
+	}
+
+	if sb.IsFormatted() {
+		sb.SUmTime = float32(time.Now().Unix())
+		if err := sb.WriteSuperBlock(path, int64(partition.Start), int64(partition.Start+int32(binary.Size(sb)))); err != nil {
+			return err
+		}
+	}
+
+	if partition.Type == 'L' {
+		if err := cmd.unmountLogicalPartition(path, partition.Name); err != nil {
+			return err
+		}
+	} else {
+		mbr := &structures.MBR{}
+		if err := mbr.ReadMBR(path); err != nil {
+			return err
+		}
+
+		mounted, err := mbr.GetPartitionByID(cmd.Id)
+		if err != nil {
+			return err
+		}
+
+		mounted.UnmountPartition()
+
+		if err := mbr.WriteMBR(path); err != nil {
+			return err
+		}
+	}
+
+	global.RemoveMountedPartition(cmd.Id)
+
+	return nil
+}
+
+func (cmd *Unmount) unmountLogicalPartition(path, name string) error {
+	mbr := &structures.MBR{}
+	if err := mbr.ReadMBR(path); err != nil {
+		return err
+	}
+
+	extended := mbr.GetExtendedPartition()
+	if extended == nil {
+		return fmt.Errorf("extended partition does not exist")
+	}
+
+	node, err := structures.FindLogicalPartition(path, extended, name)
+	if err != nil {
+		return err
+	}
+
+	node.EBR.UnmountPartition()
+
+	return node.EBR.WriteEBR(path, node.Offset, int64(extended.PartStart+extended.PartSize))
+}
+
+func (cmd *Unmount) Print() string {
+	return fmt.Sprintf("partition %s unmounted successfully", cmd.Id)
+}
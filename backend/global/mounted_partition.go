@@ -9,9 +9,10 @@ const Carnet string = "39"
 
 var (
 	MountedPartitions = make(map[string]string) // id -> path
+	MountedNames      = make(map[string]string) // id -> partition name
 )
 
-func GetMountedPartition(id string) (*structures.Partition, string, error) {
+func GetMountedPartition(id string) (*structures.MountedVolume, string, error) {
 	path := MountedPartitions[id]
 	if path == "" {
 		return nil, "", errors.New("partition not mounted with id: " + id)
@@ -23,12 +24,43 @@ func GetMountedPartition(id string) (*structures.Partition, string, error) {
 		return nil, "", err
 	}
 
-	partition, err := mbr.GetPartitionByID(id)
+	volume := &structures.MountedVolume{}
+
+	if partition, err := mbr.GetPartitionByID(id); err == nil {
+		volume.SetFromPartition(partition)
+		return volume, path, nil
+	}
+
+	extended := mbr.GetExtendedPartition()
+	if extended == nil {
+		return nil, "", errors.New("partition not found")
+	}
+
+	node, err := structures.FindLogicalPartition(path, extended, MountedNames[id])
 	if err != nil {
 		return nil, "", err
 	}
 
-	return partition, path, nil
+	if !node.EBR.IsMounted() {
+		return nil, "", errors.New("partition not mounted with id: " + id)
+	}
+
+	volume.SetFromEBR(&node.EBR, id)
+	return volume, path, nil
+}
+
+func IsPartitionMounted(path, name string) bool {
+	for id, mountedPath := range MountedPartitions {
+		if mountedPath == path && MountedNames[id] == name {
+			return true
+		}
+	}
+	return false
+}
+
+func RemoveMountedPartition(id string) {
+	delete(MountedPartitions, id)
+	delete(MountedNames, id)
 }
 
 func PrintMountedPartitions() string {
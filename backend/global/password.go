@@ -0,0 +1,68 @@
+package global
+
+import (
+	"crypto/subtle"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// HashPassword returns the salted bcrypt hash stored in users.txt for password
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %v", err)
+	}
+	return string(hash), nil
+}
+
+func isPasswordHashed(stored string) bool {
+	_, err := bcrypt.Cost([]byte(stored))
+	return err == nil
+}
+
+// checkPassword compares password with the stored one in constant time, files written before
+// passwords were hashed still have them in plain text
+func checkPassword(stored, password string) bool {
+	if isPasswordHashed(stored) {
+		return bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)) == nil
+	}
+	return subtle.ConstantTimeCompare([]byte(stored), []byte(password)) == 1
+}
+
+// VerifyPassword reports whether password is the one of the active user username
+func VerifyPassword(username, password string) bool {
+	user := GetInfoUser(username)
+	return user.Username != "" && checkPassword(user.Password, password)
+}
+
+// UpgradePassword hashes the password of an active user that is still stored in plain text,
+// password must already be checked. It returns the new hash, or "" when nothing changed
+func UpgradePassword(username, password string) (string, error) {
+	user := GetInfoUser(username)
+	if user.Username == "" || isPasswordHashed(user.Password) {
+		return "", nil
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return "", err
+	}
+
+	if err := SetPassword(username, hash); err != nil {
+		return "", err
+	}
+
+	return hash, nil
+}
+
+// SetPassword replaces the stored password of an active user, hash is written as is
+func SetPassword(username, hash string) error {
+	for i, user := range Users[username] {
+		if user.ID != "0" {
+			Users[username][i].Password = hash
+			return nil
+		}
+	}
+	return fmt.Errorf("user %s does not exist", username)
+}
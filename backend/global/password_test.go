@@ -0,0 +1,79 @@
+package global
+
+import "testing"
+
+func TestCheckPassword(t *testing.T) {
+	hash, err := HashPassword("123")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		stored   string
+		password string
+		want     bool
+	}{
+		{name: "hash matches", stored: hash, password: "123", want: true},
+		{name: "hash does not match", stored: hash, password: "1234", want: false},
+		{name: "plain text matches", stored: "123", password: "123", want: true},
+		{name: "plain text does not match", stored: "123", password: "12", want: false},
+		{name: "hash is not a password", stored: hash, password: hash, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkPassword(tt.stored, tt.password); got != tt.want {
+				t.Errorf("checkPassword() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpgradePassword(t *testing.T) {
+	hash, err := HashPassword("abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		data        string
+		username    string
+		password    string
+		wantUpgrade bool
+	}{
+		{name: "plain text is hashed", data: "1,G,root\n1,U,root,root,123\n", username: "root", password: "123", wantUpgrade: true},
+		{name: "hash is kept", data: "1,G,root\n1,U,root,root," + hash + "\n", username: "root", password: "abc"},
+		{name: "removed user is ignored", data: "1,G,root\n1,U,root,root,123\n0,U,root,ana,x\n", username: "ana", password: "x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ParserUserData(tt.data)
+
+			got, err := UpgradePassword(tt.username, tt.password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (got != "") != tt.wantUpgrade {
+				t.Fatalf("UpgradePassword() = %q, want upgrade %v", got, tt.wantUpgrade)
+			}
+			if !tt.wantUpgrade {
+				return
+			}
+
+			if !isPasswordHashed(GetInfoUser(tt.username).Password) {
+				t.Errorf("password of %s is still in plain text", tt.username)
+			}
+
+			// The upgraded users.txt still lets the user in
+			ParserUserData(ConvertToString())
+			if err := LogUserIn(tt.username, tt.password, "391A"); err != nil {
+				t.Errorf("LogUserIn() after upgrade: %v", err)
+			}
+			ClearData()
+			LoggedUser, LoggedPartition = "", ""
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package global
 
 import (
+	"backend/structures"
 	"fmt"
 	"sort"
 	"strconv"
@@ -14,6 +15,7 @@ type Group struct {
 }
 
 type User struct {
+	ID        string
 	UserGroup Group
 	Username  string
 	Password  string
@@ -24,6 +26,12 @@ var (
 	Groups          = make(map[string][]Group)
 	LoggedUser      string
 	LoggedPartition string
+	// Umask applies to the files and folders created during the session
+	Umask = structures.DefaultUmask
+
+	// The last IDs given out, removed users and groups keep theirs used so they are never reused
+	lastUserID  int
+	lastGroupID int
 )
 
 func AddGroup(name string) error {
@@ -49,13 +57,13 @@ func AddGroup(name string) error {
 
 func AddUserToGroup(username, password, groupName string) error {
 	for _, user := range Users[username] {
-		if user.UserGroup.ID != "0" {
+		if user.ID != "0" {
 			return fmt.Errorf("user already exists and is active")
 		}
 	}
 
 	for i, user := range Users[username] {
-		if user.UserGroup.ID == "0" {
+		if user.ID == "0" {
 			groupList, exists := Groups[groupName]
 			if !exists || len(groupList) == 0 {
 				return fmt.Errorf("group does not exist")
@@ -72,6 +80,7 @@ func AddUserToGroup(username, password, groupName string) error {
 				return fmt.Errorf("no active group found")
 			}
 
+			Users[username][i].ID = getNextUserID()
 			Users[username][i].UserGroup = *activeGroup
 			Users[username][i].Password = password
 			return nil
@@ -94,7 +103,7 @@ func AddUserToGroup(username, password, groupName string) error {
 		return fmt.Errorf("no active group found")
 	}
 
-	newUser := User{UserGroup: *activeGroup, Username: username, Password: password}
+	newUser := User{ID: getNextUserID(), UserGroup: *activeGroup, Username: username, Password: password}
 	Users[username] = append(Users[username], newUser)
 	return nil
 }
@@ -114,7 +123,8 @@ func RemoveGroup(name string) error {
 
 			for _, userList := range Users {
 				for j := range userList {
-					if userList[j].UserGroup.Name == name && userList[j].UserGroup.ID != "0" {
+					if userList[j].UserGroup.Name == name && userList[j].ID != "0" {
+						userList[j].ID = "0"
 						userList[j].UserGroup.ID = "0"
 					}
 				}
@@ -143,9 +153,9 @@ func RemoveUser(username string) error {
 	copy(updatedUserList, userList)
 
 	for i, user := range updatedUserList {
-		if user.UserGroup.ID != "0" {
+		if user.ID != "0" {
 
-			updatedUserList[i].UserGroup.ID = "0"
+			updatedUserList[i].ID = "0"
 			userFound = true
 		}
 	}
@@ -181,7 +191,7 @@ func ChangeUserGroup(username, groupName string) error {
 	}
 
 	for i, user := range userList {
-		if user.UserGroup.ID != "0" {
+		if user.ID != "0" {
 			userList[i].UserGroup = *activeGroup
 			Users[username] = userList
 			return nil
@@ -200,7 +210,7 @@ func GetLoggedUser() (string, string, error) {
 
 func GetInfoUser(username string) User {
 	for _, user := range Users[username] {
-		if user.UserGroup.ID != "0" {
+		if user.ID != "0" {
 			return user
 		}
 	}
@@ -216,7 +226,7 @@ func LogUserIn(username, password, partition string) error {
 
 	var validUser *User
 	for _, user := range userList {
-		if user.Password == password && user.UserGroup.ID != "0" {
+		if user.ID != "0" && checkPassword(user.Password, password) {
 			validUser = &user
 			break
 		}
@@ -228,6 +238,7 @@ func LogUserIn(username, password, partition string) error {
 
 	LoggedUser = username
 	LoggedPartition = partition
+	Umask = structures.DefaultUmask
 
 	return nil
 }
@@ -235,6 +246,7 @@ func LogUserIn(username, password, partition string) error {
 func ClearData() {
 	Users = make(map[string][]User)
 	Groups = make(map[string][]Group)
+	lastUserID, lastGroupID = 0, 0
 }
 
 func LogUserOut() (string, error) {
@@ -246,6 +258,7 @@ func LogUserOut() (string, error) {
 	temp := LoggedUser
 	LoggedUser = ""
 	LoggedPartition = ""
+	Umask = structures.DefaultUmask
 	return temp, nil
 }
 
@@ -255,6 +268,33 @@ func IsUserLogged() bool {
 
 func ParserUserData(data string) {
 	ClearData()
+
+	groups, users, lastUser, lastGroup := parseUsersFile(data)
+
+	for _, group := range groups {
+		Groups[group.Name] = append(Groups[group.Name], group)
+	}
+
+	for _, user := range users {
+		Users[user.Username] = append(Users[user.Username], user)
+	}
+
+	lastUserID, lastGroupID = lastUser, lastGroup
+}
+
+// ParseUsersFile reads the content of users.txt in order, without touching the session data.
+// Group lines are GID,G,group and user lines are UID,U,group,user,password, an ID of 0 marks
+// a removed group or user. The line 0,I,UID,GID keeps the last IDs given out
+func ParseUsersFile(data string) ([]Group, []User) {
+	groups, users, _, _ := parseUsersFile(data)
+	return groups, users
+}
+
+func parseUsersFile(data string) ([]Group, []User, int, int) {
+	var groups []Group
+	var users []User
+	lastUser, lastGroup := -1, -1
+
 	lines := strings.Split(data, "\n")
 
 	for _, line := range lines {
@@ -274,94 +314,203 @@ func ParserUserData(data string) {
 
 		switch len(parts) {
 		case 3:
-			Groups[name] = append(Groups[name], Group{ID: id, Type: typ, Name: name})
+			groups = append(groups, Group{ID: id, Type: typ, Name: name})
+		case 4:
+			if typ == "I" {
+				lastUser, _ = strconv.Atoi(name)
+				lastGroup, _ = strconv.Atoi(strings.TrimSpace(parts[3]))
+			}
 		case 5:
 			username := strings.TrimSpace(parts[3])
 			password := strings.TrimSpace(parts[4])
-			Users[username] = append(Users[username], User{UserGroup: Group{ID: id, Type: typ, Name: name}, Username: username, Password: password})
+			users = append(users, User{ID: id, UserGroup: Group{ID: "0", Type: "G", Name: name}, Username: username, Password: password})
 		}
 	}
-}
 
-func getNextGroupID() string {
-	maxID := 0
+	if lastUser == -1 {
+		lastUser, lastGroup = migrateIDs(groups, users)
+	}
 
-	for _, groupList := range Groups {
-		for _, group := range groupList {
-			id, err := strconv.Atoi(group.ID)
-			if err == nil && id > maxID {
-				maxID = id
+	for i := range users {
+		for _, group := range groups {
+			if group.Name == users[i].UserGroup.Name && group.ID != "0" {
+				users[i].UserGroup = group
+				break
 			}
 		}
+
+		if id, err := strconv.Atoi(users[i].ID); err == nil && id > lastUser {
+			lastUser = id
+		}
 	}
 
-	return strconv.Itoa(maxID + 1)
+	for _, group := range groups {
+		if id, err := strconv.Atoi(group.ID); err == nil && id > lastGroup {
+			lastGroup = id
+		}
+	}
+
+	return groups, users, lastUser, lastGroup
 }
 
-func ConvertToString() string {
-	var sb strings.Builder
+// migrateIDs renumbers files written before users had their own ID, when the first column of a user
+// was the ID of its group. The root user and group get 1, the rest follow in file order.
+// It returns the last user and group IDs given out
+func migrateIDs(groups []Group, users []User) (int, int) {
+	next := 2
+	for i := range groups {
+		switch {
+		case groups[i].ID == "0":
+		case groups[i].Name == "root":
+			groups[i].ID = "1"
+		default:
+			groups[i].ID = strconv.Itoa(next)
+			next++
+		}
+	}
+	lastGroup := next - 1
+
+	next = 2
+	for i := range users {
+		switch {
+		case users[i].ID == "0":
+		case users[i].Username == "root":
+			users[i].ID = "1"
+		default:
+			users[i].ID = strconv.Itoa(next)
+			next++
+		}
+	}
 
-	groupMap := make(map[string]Group)
-	userMap := make(map[string][]User)
-	var usersWithoutGroup []User
+	return next - 1, lastGroup
+}
 
-	for _, groupList := range Groups {
-		for _, group := range groupList {
-			groupMap[group.ID] = group
+func FindGroupName(groups []Group, id string) string {
+	for _, group := range groups {
+		if group.ID == id {
+			return group.Name
 		}
 	}
+	return id
+}
 
-	for _, userList := range Users {
-		for _, user := range userList {
-			if user.UserGroup.ID == "0" {
-				usersWithoutGroup = append(usersWithoutGroup, user)
-			} else {
-				userMap[user.UserGroup.ID] = append(userMap[user.UserGroup.ID], user)
-			}
+func FindUserName(users []User, id string) string {
+	for _, user := range users {
+		if user.ID == id {
+			return user.Username
 		}
 	}
+	return id
+}
+
+func getNextGroupID() string {
+	lastGroupID++
+	return strconv.Itoa(lastGroupID)
+}
+
+func getNextUserID() string {
+	lastUserID++
+	return strconv.Itoa(lastUserID)
+}
+
+func ConvertToString() string {
+	var sb strings.Builder
+
+	var groups []Group
+	for _, groupList := range Groups {
+		groups = append(groups, groupList...)
+	}
 
-	var ids []string
-	for id := range groupMap {
-		ids = append(ids, id)
+	var users []User
+	for _, userList := range Users {
+		users = append(users, userList...)
 	}
 
-	sort.Slice(ids, func(i, j int) bool {
-		id1, _ := strconv.Atoi(ids[i])
-		id2, _ := strconv.Atoi(ids[j])
-		return id1 < id2
+	sort.Slice(groups, func(i, j int) bool {
+		return lessByID(groups[i].ID, groups[i].Name, groups[j].ID, groups[j].Name)
 	})
+	sort.Slice(users, func(i, j int) bool {
+		return lessByID(users[i].ID, users[i].Username, users[j].ID, users[j].Username)
+	})
+
+	sb.WriteString(fmt.Sprintf("0,I,%d,%d\n", lastUserID, lastGroupID))
 
-	for _, id := range ids {
-		group := groupMap[id]
+	written := make(map[int]bool)
+
+	for _, group := range groups {
 		sb.WriteString(strings.Join([]string{group.ID, group.Type, group.Name}, ",") + "\n")
 
-		if users, exists := userMap[group.ID]; exists {
-			userSet := make(map[string]bool)
-			for _, user := range users {
-				if !userSet[user.Username] {
-					sb.WriteString(strings.Join([]string{
-						user.UserGroup.ID,
-						"U",
-						user.UserGroup.Name,
-						user.Username,
-						user.Password,
-					}, ",") + "\n")
-					userSet[user.Username] = true
-				}
+		if group.ID == "0" {
+			continue
+		}
+
+		for i, user := range users {
+			if user.ID != "0" && user.UserGroup.Name == group.Name {
+				sb.WriteString(userLine(user))
+				written[i] = true
 			}
 		}
 	}
 
-	for _, user := range usersWithoutGroup {
-		sb.WriteString(strings.Join([]string{
-			"0",
-			"U",
-			user.UserGroup.Name,
-			user.Username,
-			user.Password,
-		}, ",") + "\n")
+	for i, user := range users {
+		if !written[i] {
+			sb.WriteString(userLine(user))
+		}
 	}
 
 	return sb.String()
 }
+
+func userLine(user User) string {
+	return strings.Join([]string{
+		user.ID,
+		"U",
+		user.UserGroup.Name,
+		user.Username,
+		user.Password,
+	}, ",") + "\n"
+}
+
+// lessByID orders by numeric ID, and by name the removed entries that share the ID 0
+func lessByID(id1, name1, id2, name2 string) bool {
+	n1, _ := strconv.Atoi(id1)
+	n2, _ := strconv.Atoi(id2)
+	if n1 != n2 {
+		return n1 < n2
+	}
+	return name1 < name2
+}
+
+// GetLoggedUserIDs returns the user and group ids of the logged user, Users and Groups must be loaded
+func GetLoggedUserIDs() (int32, int32, error) {
+	if LoggedUser == "" {
+		return 0, 0, fmt.Errorf("no user logged")
+	}
+
+	return GetUserIDs(LoggedUser)
+}
+
+// GetUserIDs returns the user and group ids of an active user, Users and Groups must be loaded
+func GetUserIDs(username string) (int32, int32, error) {
+	user := GetInfoUser(username)
+	if user.Username == "" {
+		return 0, 0, fmt.Errorf("user %s does not exist", username)
+	}
+
+	uid, err := strconv.Atoi(user.ID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid user id: %s", user.ID)
+	}
+
+	for _, group := range Groups[user.UserGroup.Name] {
+		if group.ID != "0" {
+			gid, err := strconv.Atoi(group.ID)
+			if err != nil {
+				return 0, 0, fmt.Errorf("invalid group id: %s", group.ID)
+			}
+			return int32(uid), int32(gid), nil
+		}
+	}
+
+	return 0, 0, fmt.Errorf("group %s of user %s does not exist", user.UserGroup.Name, username)
+}
@@ -0,0 +1,152 @@
+package global
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParserUserDataIDs(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		username string
+		wantUID  int32
+		wantGID  int32
+	}{
+		{
+			name:     "new file",
+			data:     "1,G,root\n1,U,root,root,123\n",
+			username: "root", wantUID: 1, wantGID: 1,
+		},
+		{
+			name:     "old file with root listed second",
+			data:     "1,G,admins\n2,G,root\n1,U,admins,admin,x\n2,U,root,root,123\n",
+			username: "root", wantUID: 1, wantGID: 1,
+		},
+		{
+			name:     "old file renumbers the rest",
+			data:     "1,G,admins\n2,G,root\n1,U,admins,admin,x\n2,U,root,root,123\n",
+			username: "admin", wantUID: 2, wantGID: 2,
+		},
+		{
+			name:     "old file with users sharing the group id",
+			data:     "1,G,root\n2,G,g\n1,U,root,root,123\n2,U,g,ana,x\n2,U,g,luis,y\n",
+			username: "luis", wantUID: 3, wantGID: 2,
+		},
+		{
+			name:     "removed entries are skipped by the migration",
+			data:     "1,G,root\n0,G,old\n2,G,g\n1,U,root,root,123\n0,U,old,ana,x\n2,U,g,luis,y\n",
+			username: "luis", wantUID: 2, wantGID: 2,
+		},
+		{
+			name:     "counter line keeps the ids",
+			data:     "0,I,7,5\n1,G,root\n5,G,g\n1,U,root,root,123\n7,U,g,ana,x\n",
+			username: "ana", wantUID: 7, wantGID: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ParserUserData(tt.data)
+
+			uid, gid, err := GetUserIDs(tt.username)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if uid != tt.wantUID || gid != tt.wantGID {
+				t.Errorf("GetUserIDs(%s) = %d, %d, want %d, %d", tt.username, uid, gid, tt.wantUID, tt.wantGID)
+			}
+		})
+	}
+}
+
+func TestNextIDsAreNotReused(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		change  func() error
+		want    string
+		wantUID int32
+		wantGID int32
+	}{
+		{
+			name:    "user after a removed one",
+			data:    "0,I,3,2\n1,G,root\n2,G,g\n1,U,root,root,123\n2,U,g,ana,x\n0,U,g,luis,y\n",
+			change:  func() error { return AddUserToGroup("pepe", "z", "g") },
+			want:    "pepe",
+			wantUID: 4, wantGID: 2,
+		},
+		{
+			name:    "removed user added again",
+			data:    "0,I,3,2\n1,G,root\n2,G,g\n1,U,root,root,123\n2,U,g,ana,x\n0,U,g,luis,y\n",
+			change:  func() error { return AddUserToGroup("luis", "y", "g") },
+			want:    "luis",
+			wantUID: 4, wantGID: 2,
+		},
+		{
+			name: "group after a removed one",
+			data: "0,I,1,3\n1,G,root\n2,G,g\n0,G,h\n1,U,root,root,123\n",
+			change: func() error {
+				if err := AddGroup("k"); err != nil {
+					return err
+				}
+				return AddUserToGroup("ana", "x", "k")
+			},
+			want:    "ana",
+			wantUID: 2, wantGID: 4,
+		},
+		{
+			name:    "counter behind the file",
+			data:    "0,I,1,1\n1,G,root\n1,U,root,root,123\n6,U,root,ana,x\n",
+			change:  func() error { return AddUserToGroup("luis", "y", "root") },
+			want:    "luis",
+			wantUID: 7, wantGID: 1,
+		},
+		{
+			name: "counters survive a write",
+			data: "0,I,3,1\n1,G,root\n1,U,root,root,123\n0,U,root,ana,x\n",
+			change: func() error {
+				ParserUserData(ConvertToString())
+				return AddUserToGroup("luis", "y", "root")
+			},
+			want:    "luis",
+			wantUID: 4, wantGID: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ParserUserData(tt.data)
+
+			if err := tt.change(); err != nil {
+				t.Fatal(err)
+			}
+
+			uid, gid, err := GetUserIDs(tt.want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if uid != tt.wantUID || gid != tt.wantGID {
+				t.Errorf("GetUserIDs(%s) = %d, %d, want %d, %d", tt.want, uid, gid, tt.wantUID, tt.wantGID)
+			}
+		})
+	}
+}
+
+func TestConvertToStringWritesCounters(t *testing.T) {
+	ParserUserData("1,G,root\n1,U,root,root,123\n")
+
+	if err := AddGroup("g"); err != nil {
+		t.Fatal(err)
+	}
+
+	data := ConvertToString()
+	if !strings.HasPrefix(data, "0,I,1,2\n") {
+		t.Errorf("ConvertToString() = %q, want the counter line first", data)
+	}
+
+	groups, users := ParseUsersFile(data)
+	if len(groups) != 2 || len(users) != 1 {
+		t.Errorf("ParseUsersFile() = %d groups, %d users, want 2 and 1", len(groups), len(users))
+	}
+}
@@ -111,110 +111,127 @@ func (sb *SuperBlock) WriteFile(path string, index int32, filePath []string, con
 	return 0, nil
 }
 
+// writeFileContent replaces the whole content of a file, releasing its old blocks first
 func (sb *SuperBlock) writeFileContent(path string, inode *Inode, content string, index int32) (int, error) {
-	inode.IMTime = float32(time.Now().Unix())
-	var err error
-	for i, blockIndex := range inode.IBlock[:12] {
-		if content == "" {
-			break
-		}
-		if blockIndex == -1 {
-			inode.IBlock[i] = sb.SBlocksCount
-			inode.IMTime = float32(time.Now().Unix())
+	oldBlocks := sb.getAllBlocks(path, inode)
 
-			content, err = sb.CreateFileBlock(path, content)
-			if err != nil {
-				return 0, err
-			}
-		} else {
-			content, err = sb.WriteFileBlock(path, blockIndex, content)
-			if err != nil {
-				return 0, err
-			}
-		}
+	dataBlocks := int32((len(content) + 63) / 64)
+	totalBlocks, err := fileBlocksNeeded(dataBlocks)
+	if err != nil {
+		return 0, err
 	}
 
-	for i, blockIndex := range inode.IBlock[12:] {
-		if content == "" {
-			break
-		}
+	if totalBlocks > sb.SFreeBlockCount+int32(len(oldBlocks)) {
+		return 0, fmt.Errorf("no free blocks")
+	}
 
-		if blockIndex == -1 {
-			inode.IBlock[i] = sb.SBlocksCount
-			inode.IMTime = float32(time.Now().Unix())
+	for _, blockIndex := range oldBlocks {
+		if err := sb.FreeBitmapBlock(path, blockIndex); err != nil {
+			return 0, err
+		}
+	}
+	for i := range inode.IBlock {
+		inode.IBlock[i] = -1
+	}
 
-			if err := sb.CreatePointerBlock(path, i); err != nil {
-				return 0, err
-			}
+	if err := sb.ReserveBlockRun(path, totalBlocks); err != nil {
+		return 0, err
+	}
 
-			content, err = sb.CreateFileBlock(path, content)
-			if err != nil {
-				return 0, err
-			}
-		} else {
+	remaining := content
+	for i := 0; i < 12 && remaining != ""; i++ {
+		inode.IBlock[i] = sb.SFirstBlo
+		if remaining, err = sb.CreateFileBlock(path, remaining); err != nil {
+			return 0, err
+		}
+	}
 
+	for level := int32(0); level < 3 && remaining != ""; level++ {
+		if inode.IBlock[12+level], remaining, err = sb.writePointerContent(path, level, remaining); err != nil {
+			return 0, err
 		}
 	}
 
+	now := float32(time.Now().Unix())
+	inode.ISize = int32(len(content))
+	inode.IMTime = now
+	inode.IAtime = now
+
 	if err := inode.WriteInode(path, int64(sb.SInodeStart+index*sb.SInodeSize),
 		int64(sb.SInodeStart+(index+1)*sb.SInodeSize)); err != nil {
 		return 0, err
 	}
 
-	return 0, nil
+	return len(content), nil
 }
 
-func (sb *SuperBlock) writePointerContent(path string, blockIndex, level int32, content string) (string, error) {
-	block := &PointerBlock{}
-	blockPath := int64(sb.SBlockStart + blockIndex*sb.SBlockSize)
-	var err error
-	err = block.ReadPointerBlock(path, blockPath)
+// writePointerContent creates a pointer block of the given level, level 0 points to file blocks,
+// and fills it with as much content as it can hold
+func (sb *SuperBlock) writePointerContent(path string, level int32, content string) (int32, string, error) {
+	blockIndex, err := sb.AllocateBlock(path)
 	if err != nil {
-		return "", err
+		return -1, "", err
 	}
 
-	for i, pointer := range block.PPointers {
+	block := &PointerBlock{}
+	block.DefaultValue()
+
+	for i := range block.PPointers {
 		if content == "" {
 			break
 		}
-		if pointer == -1 {
-			block.PPointers[i] = sb.SBlocksCount
-			if err := block.WritePointerBlock(path, blockPath, blockPath+int64(sb.SBlockSize)); err != nil {
-				return "", err
-			}
-
-			if level != 0 {
-				if err := sb.CreatePointerBlock(path, int(level-1)); err != nil {
-					return "", err
-				}
-			}
 
-			content, err = sb.CreateFileBlock(path, content)
-			if err != nil {
-				return "", err
+		if level == 0 {
+			block.PPointers[i] = sb.SFirstBlo
+			if content, err = sb.CreateFileBlock(path, content); err != nil {
+				return -1, "", err
 			}
 		} else {
-			if level == 0 {
-				content, err = sb.WriteFileBlock(path, pointer, content)
-				if err != nil {
-					return "", err
-				}
-			} else {
-				content, err = sb.writePointerContent(path, pointer, level-1, content)
-				if err != nil {
-					return "", err
-				}
+			if block.PPointers[i], content, err = sb.writePointerContent(path, level-1, content); err != nil {
+				return -1, "", err
 			}
 		}
 	}
 
-	return content, nil
+	blockPath := int64(sb.SBlockStart + blockIndex*sb.SBlockSize)
+	if err := block.WritePointerBlock(path, blockPath, blockPath+int64(sb.SBlockSize)); err != nil {
+		return -1, "", err
+	}
+
+	return blockIndex, content, nil
+}
+
+// fileBlocksNeeded returns how many blocks, pointer blocks included, a file of dataBlocks blocks uses
+func fileBlocksNeeded(dataBlocks int32) (int32, error) {
+	total := dataBlocks
+	remaining := dataBlocks - 12
+	capacity := int32(1)
+
+	for level := 0; level < 3 && remaining > 0; level++ {
+		capacity *= 16
+		// One pointer block per 16^k data blocks at each depth of this level
+		for perBlock := capacity; perBlock >= 16; perBlock /= 16 {
+			total += (min(remaining, capacity) + perBlock - 1) / perBlock
+		}
+		remaining -= capacity
+	}
+
+	if remaining > 0 {
+		return 0, fmt.Errorf("file too large")
+	}
+
+	return total, nil
 }
 
-// CreateInode creates a new inode in the filesystem
-func (sb *SuperBlock) CreateInode(path string, isFile bool) error {
+// CreateInode creates a new inode in the filesystem owned by uid and gid, umask is applied to its permissions.
+// A folder gets its first block right away, with ".." pointing to parentIndex. It returns the index of the inode
+func (sb *SuperBlock) CreateInode(path string, isFile bool, parentIndex, uid, gid int32, umask [3]byte) (int32, error) {
 	if sb.SFreeInodeCount == 0 {
-		return fmt.Errorf("no free inodes")
+		return -1, fmt.Errorf("no free inodes")
+	}
+
+	if !isFile && sb.SFreeBlockCount == 0 {
+		return -1, fmt.Errorf("no free blocks")
 	}
 
 	newInode := &Inode{}
@@ -224,38 +241,60 @@ func (sb *SuperBlock) CreateInode(path string, isFile bool) error {
 	} else {
 		newInode.IType = '0'
 	}
-	newInode.IPerm = [3]byte{'6', '6', '4'}
+	newInode.IPerm = NewPermission(isFile, umask)
+	newInode.IuId = uid
+	newInode.IGid = gid
 
-	if err := newInode.WriteInode(path, int64(sb.SFirstIno), int64(sb.SFirstIno+sb.SInodeSize)); err != nil {
-		return err
+	index, err := sb.AllocateInode(path)
+	if err != nil {
+		return -1, err
 	}
 
-	if err := sb.UpdateBitmapInode(path); err != nil {
-		return err
+	if !isFile {
+		newBlock := &FolderBlock{}
+		newBlock.DefaultValue()
+		newBlock.BContent[0].BInode = index
+		newBlock.BContent[1].BInode = parentIndex
+
+		blockIndex, err := sb.AllocateBlock(path)
+		if err != nil {
+			_ = sb.FreeBitmapInode(path, index)
+			return -1, err
+		}
+
+		if err := newBlock.WriteFolderBlock(path, int64(sb.SBlockStart+blockIndex*sb.SBlockSize), int64(sb.SBlockStart+(blockIndex+1)*sb.SBlockSize)); err != nil {
+			return -1, err
+		}
+		newInode.IBlock[0] = blockIndex
 	}
 
-	return nil
+	if err := newInode.WriteInode(path, int64(sb.SInodeStart+index*sb.SInodeSize), int64(sb.SInodeStart+(index+1)*sb.SInodeSize)); err != nil {
+		return -1, err
+	}
+
+	return index, nil
 }
 
 // CreateFolderBlock creates a new folder block in the filesystem
-func (sb *SuperBlock) CreateFolderBlock(path, name string, indexInode int32) error {
+func (sb *SuperBlock) CreateFolderBlock(path, name string, indexInode, parentIndex, childIndex int32) error {
 	if sb.SFreeBlockCount == 0 {
 		return fmt.Errorf("no free blocks")
 	}
 
 	newBlock := &FolderBlock{}
 	newBlock.DefaultValue()
-	newBlock.BContent[0].BInode = indexInode // FIX CURRENT
-	newBlock.BContent[1].BInode = indexInode // FIX FATHER
+	newBlock.BContent[0].BInode = indexInode
+	newBlock.BContent[1].BInode = parentIndex
 
-	newBlock.BContent[2].BInode = sb.SInodesCount
+	newBlock.BContent[2].BInode = childIndex
 	copy(newBlock.BContent[2].BName[:], name)
 
-	if err := newBlock.WriteFolderBlock(path, int64(sb.SFirstBlo), int64(sb.SFirstBlo+sb.SBlockSize)); err != nil {
+	index, err := sb.AllocateBlock(path)
+	if err != nil {
 		return err
 	}
 
-	if err := sb.UpdateBitmapBlock(path); err != nil {
+	if err := newBlock.WriteFolderBlock(path, int64(sb.SBlockStart+index*sb.SBlockSize), int64(sb.SBlockStart+(index+1)*sb.SBlockSize)); err != nil {
 		return err
 	}
 
@@ -272,29 +311,12 @@ func (sb *SuperBlock) CreateFileBlock(path string, content string) (string, erro
 	toWrite := min(len(content), 64)
 	copy(newBlock.BContent[:], content[:toWrite])
 
-	if err := newBlock.WriteFileBlock(path, int64(sb.SFirstBlo), int64(sb.SFirstBlo+sb.SBlockSize)); err != nil {
-		return "", err
-	}
-
-	if err := sb.UpdateBitmapBlock(path); err != nil {
-		return "", err
-	}
-
-	return content[toWrite:], nil
-}
-
-func (sb *SuperBlock) WriteFileBlock(path string, index int32, content string) (string, error) {
-	block := &FileBlock{}
-	blockPath := int64(sb.SBlockStart + index*sb.SBlockSize)
-
-	if err := block.ReadFileBlock(path, blockPath); err != nil {
+	index, err := sb.AllocateBlock(path)
+	if err != nil {
 		return "", err
 	}
 
-	toWrite := min(len(content), 64)
-	copy(block.BContent[:], content[:toWrite])
-
-	if err := block.WriteFileBlock(path, blockPath, blockPath+int64(sb.SBlockSize)); err != nil {
+	if err := newBlock.WriteFileBlock(path, int64(sb.SBlockStart+index*sb.SBlockSize), int64(sb.SBlockStart+(index+1)*sb.SBlockSize)); err != nil {
 		return "", err
 	}
 
@@ -307,15 +329,17 @@ func (sb *SuperBlock) CreatePointerBlock(path string, level int) error {
 		return fmt.Errorf("no free blocks")
 	}
 
-	newBlock := &PointerBlock{}
-	newBlock.DefaultValue()
-	newBlock.PPointers[0] = sb.SBlocksCount + 1
-
-	if err := newBlock.WritePointerBlock(path, int64(sb.SFirstBlo), int64(sb.SFirstBlo+sb.SBlockSize)); err != nil {
+	index, err := sb.AllocateBlock(path)
+	if err != nil {
 		return err
 	}
 
-	if err := sb.UpdateBitmapBlock(path); err != nil {
+	// The first pointer goes to the block allocated right after this one
+	newBlock := &PointerBlock{}
+	newBlock.DefaultValue()
+	newBlock.PPointers[0] = sb.SFirstBlo
+
+	if err := newBlock.WritePointerBlock(path, int64(sb.SBlockStart+index*sb.SBlockSize), int64(sb.SBlockStart+(index+1)*sb.SBlockSize)); err != nil {
 		return err
 	}
 
@@ -327,7 +351,7 @@ func (sb *SuperBlock) CreatePointerBlock(path string, level int) error {
 }
 
 // CreateNewInode creates a new inode in the filesystem (File/Folder)
-func (sb *SuperBlock) CreateNewInode(path string, filePath []string, indexInode int32, isFile, root bool) error {
+func (sb *SuperBlock) CreateNewInode(path string, filePath []string, indexInode int32, isFile, root bool, uid, gid int32, umask [3]byte) error {
 	inode := &Inode{}
 	inodePath := int64(sb.SInodeStart + indexInode*sb.SInodeSize)
 
@@ -340,76 +364,98 @@ func (sb *SuperBlock) CreateNewInode(path string, filePath []string, indexInode
 	}
 
 	if len(filePath) == 1 {
-		return sb.CreatePath(path, filePath[0], inode, isFile, indexInode)
+		return sb.CreatePath(path, filePath[0], inode, isFile, indexInode, uid, gid, umask)
 	}
 
 	newIndexInode := sb.findInodeInBlock(path, filePath[0], inode)
 
 	if newIndexInode != -1 {
-		return sb.CreateNewInode(path, filePath[1:], newIndexInode, isFile, root)
+		return sb.CreateNewInode(path, filePath[1:], newIndexInode, isFile, root, uid, gid, umask)
 	}
 
 	if root {
-		if err := sb.CreatePath(path, filePath[0], inode, false, indexInode); err != nil {
+		if err := sb.CreatePath(path, filePath[0], inode, false, indexInode, uid, gid, umask); err != nil {
 			return err
 		}
 		newIndexInode := sb.findInodeInBlock(path, filePath[0], inode)
-		return sb.CreateNewInode(path, filePath[1:], newIndexInode, isFile, root)
+		return sb.CreateNewInode(path, filePath[1:], newIndexInode, isFile, root, uid, gid, umask)
+	}
+
+	return nil
+}
+
+// CreatePath creates a new path in the filesystem. The inode is allocated before it is linked in the folder
+// indexInode, and released again if the folder has no room for the entry
+func (sb *SuperBlock) CreatePath(path, name string, inode *Inode, isFile bool, indexInode, uid, gid int32, umask [3]byte) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+
+	childIndex, err := sb.CreateInode(path, isFile, indexInode, uid, gid, umask)
+	if err != nil {
+		return err
+	}
+
+	if err := sb.AddEntry(path, name, inode, indexInode, childIndex); err != nil {
+		_ = sb.freeInode(path, childIndex)
+		return err
 	}
 
 	return nil
 }
 
-// CreatePath creates a new path in the filesystem
-func (sb *SuperBlock) CreatePath(path, name string, inode *Inode, isFile bool, indexInode int32) error {
+// AddEntry links childIndex as name in the folder indexInode, creating folder or pointer blocks if needed
+func (sb *SuperBlock) AddEntry(path, name string, inode *Inode, indexInode, childIndex int32) error {
+	parentIndex := sb.folderParent(path, inode, indexInode)
+
 	for i, blockIndex := range inode.IBlock[:12] {
 		if blockIndex == -1 {
-			inode.IBlock[i] = sb.SBlocksCount
+			inode.IBlock[i] = sb.SFirstBlo
 			inode.IMTime = float32(time.Now().Unix())
 
-			if err := sb.CreateBlockAndWriteInode(path, name, inode, indexInode); err != nil {
+			if err := sb.CreateBlockAndWriteInode(path, name, inode, indexInode, parentIndex, childIndex); err != nil {
 				return err
 			}
 		} else {
-			if condition, err := sb.addContentToFolderBlock(path, name, blockIndex); err != nil {
+			if condition, err := sb.addContentToFolderBlock(path, name, blockIndex, childIndex); err != nil {
 				return err
 			} else if !condition {
 				continue
 			}
 		}
 
-		return sb.CreateInode(path, isFile)
+		return nil
 	}
 
 	for i, blockIndex := range inode.IBlock[12:] {
 		if blockIndex == -1 {
-			inode.IBlock[i+12] = sb.SBlocksCount
+			inode.IBlock[i+12] = sb.SFirstBlo
 			inode.IMTime = float32(time.Now().Unix())
 
 			if err := sb.CreatePointerBlock(path, i); err != nil {
 				return err
 			}
 
-			if err := sb.CreateBlockAndWriteInode(path, name, inode, indexInode); err != nil {
+			if err := sb.CreateBlockAndWriteInode(path, name, inode, indexInode, parentIndex, childIndex); err != nil {
 				return err
 			}
 
 		} else {
-			if condition, err := sb.addContentToPointerBlock(path, name, blockIndex, indexInode, int32(i)); err != nil {
+			if condition, err := sb.addContentToPointerBlock(path, name, blockIndex, indexInode, parentIndex, childIndex, int32(i)); err != nil {
 				return err
 			} else if !condition {
 				continue
 			}
 		}
 
-		return sb.CreateInode(path, isFile)
+		return nil
 	}
 
 	return fmt.Errorf("no free blocks")
 }
 
 // CreateBlockAndWriteInode creates a new block and writes the inode in the filesystem
-func (sb *SuperBlock) CreateBlockAndWriteInode(path, name string, inode *Inode, indexInode int32) error {
+func (sb *SuperBlock) CreateBlockAndWriteInode(path, name string, inode *Inode, indexInode, parentIndex, childIndex int32) error {
 	inodeStart := int64(sb.SInodeStart + indexInode*sb.SInodeSize)
 	inodeEnd := int64(sb.SInodeStart + (indexInode+1)*sb.SInodeSize)
 
@@ -417,7 +463,7 @@ func (sb *SuperBlock) CreateBlockAndWriteInode(path, name string, inode *Inode,
 		return err
 	}
 
-	if err := sb.CreateFolderBlock(path, name, indexInode); err != nil {
+	if err := sb.CreateFolderBlock(path, name, indexInode, parentIndex, childIndex); err != nil {
 		return err
 	}
 
@@ -425,19 +471,27 @@ func (sb *SuperBlock) CreateBlockAndWriteInode(path, name string, inode *Inode,
 }
 
 // addContentToFolderBlock adds a new content to a folder block
-func (sb *SuperBlock) addContentToFolderBlock(path, name string, blockIndex int32) (bool, error) {
+func (sb *SuperBlock) addContentToFolderBlock(path, name string, blockIndex, childIndex int32) (bool, error) {
 	block := &FolderBlock{}
 
 	if err := block.ReadFolderBlock(path, int64(sb.SBlockStart+blockIndex*sb.SBlockSize)); err != nil {
 		return false, err
 	}
 
-	if block.BContent[3].BInode != -1 {
+	slot := -1
+	for i := 2; i < len(block.BContent); i++ {
+		if block.BContent[i].BInode == -1 {
+			slot = i
+			break
+		}
+	}
+
+	if slot == -1 {
 		return false, nil
 	}
 
-	copy(block.BContent[3].BName[:], name)
-	block.BContent[3].BInode = sb.SInodesCount
+	block.BContent[slot] = FolderContent{BInode: childIndex}
+	copy(block.BContent[slot].BName[:], name)
 
 	if err := block.WriteFolderBlock(path, int64(sb.SBlockStart+blockIndex*sb.SBlockSize),
 		int64(sb.SBlockStart+(blockIndex+1)*sb.SBlockSize)); err != nil {
@@ -448,7 +502,7 @@ func (sb *SuperBlock) addContentToFolderBlock(path, name string, blockIndex int3
 }
 
 // addContentToPointerBlock adds a new content to a pointer block
-func (sb *SuperBlock) addContentToPointerBlock(path, name string, blockIndex, indexInode, level int32) (bool, error) {
+func (sb *SuperBlock) addContentToPointerBlock(path, name string, blockIndex, indexInode, parentIndex, childIndex, level int32) (bool, error) {
 	block := &PointerBlock{}
 
 	if err := block.ReadPointerBlock(path, int64(sb.SBlockStart+blockIndex*sb.SBlockSize)); err != nil {
@@ -457,7 +511,7 @@ func (sb *SuperBlock) addContentToPointerBlock(path, name string, blockIndex, in
 
 	for i, pointer := range block.PPointers {
 		if pointer == -1 {
-			block.PPointers[i] = sb.SBlocksCount
+			block.PPointers[i] = sb.SFirstBlo
 
 			if err := block.WritePointerBlock(path, int64(sb.SBlockStart+blockIndex*sb.SBlockSize),
 				int64(sb.SBlockStart+(blockIndex+1)*sb.SBlockSize)); err != nil {
@@ -468,14 +522,14 @@ func (sb *SuperBlock) addContentToPointerBlock(path, name string, blockIndex, in
 					return false, err
 				}
 			}
-			if err := sb.CreateFolderBlock(path, name, sb.SInodesCount); err != nil {
+			if err := sb.CreateFolderBlock(path, name, indexInode, parentIndex, childIndex); err != nil {
 				return false, err
 			}
 			return true, nil
 		}
 
 		if level == 0 {
-			if condition, err := sb.addContentToFolderBlock(path, name, pointer); err != nil {
+			if condition, err := sb.addContentToFolderBlock(path, name, pointer, childIndex); err != nil {
 				return false, err
 			} else if !condition {
 				continue
@@ -483,7 +537,7 @@ func (sb *SuperBlock) addContentToPointerBlock(path, name string, blockIndex, in
 				return true, nil
 			}
 		} else {
-			if condition, err := sb.addContentToPointerBlock(path, name, pointer, indexInode, level-1); err != nil {
+			if condition, err := sb.addContentToPointerBlock(path, name, pointer, indexInode, parentIndex, childIndex, level-1); err != nil {
 				return false, err
 			} else if !condition {
 				continue
@@ -571,3 +625,506 @@ func (sb *SuperBlock) finInodeInPointerBlock(path, part string, blockIndex, leve
 
 	return -1
 }
+
+// GetInodeIndex returns the index of the inode at the given path, -1 if it does not exist
+func (sb *SuperBlock) GetInodeIndex(path string, filePath []string) int32 {
+	index := int32(0)
+
+	for _, part := range filePath {
+		inode := &Inode{}
+		if err := inode.ReadInode(path, int64(sb.SInodeStart+index*sb.SInodeSize)); err != nil {
+			return -1
+		}
+
+		if inode.IType != '0' {
+			return -1
+		}
+
+		index = sb.findInodeInBlock(path, part, inode)
+		if index == -1 {
+			return -1
+		}
+	}
+
+	return index
+}
+
+// ResolvePath walks filePath from the root folder, the user needs execute permission on every folder
+// it goes through. It returns the index of the last inode found and how many parts of filePath were resolved
+func (sb *SuperBlock) ResolvePath(path string, filePath []string, uid, gid int32) (int32, int, error) {
+	index := int32(0)
+
+	for i, part := range filePath {
+		inode := &Inode{}
+		if err := inode.ReadInode(path, int64(sb.SInodeStart+index*sb.SInodeSize)); err != nil {
+			return -1, i, err
+		}
+
+		if inode.IType != '0' {
+			return index, i, nil
+		}
+
+		if !inode.HasPermission(uid, gid, PermissionExecute) {
+			return -1, i, permissionDenied(filePath[:i])
+		}
+
+		next := sb.findInodeInBlock(path, part, inode)
+		if next == -1 {
+			return index, i, nil
+		}
+		index = next
+	}
+
+	return index, len(filePath), nil
+}
+
+// CheckAccess returns the index of the inode at filePath if the user can reach it and has permission on it,
+// a permission of 0 only checks the path can be traversed
+func (sb *SuperBlock) CheckAccess(path string, filePath []string, uid, gid int32, permission byte) (int32, error) {
+	index, found, err := sb.ResolvePath(path, filePath, uid, gid)
+	if err != nil {
+		return -1, err
+	}
+
+	if found < len(filePath) {
+		return -1, fmt.Errorf("path not found: /%s", strings.Join(filePath, "/"))
+	}
+
+	inode := &Inode{}
+	if err := inode.ReadInode(path, int64(sb.SInodeStart+index*sb.SInodeSize)); err != nil {
+		return -1, err
+	}
+
+	if !inode.HasPermission(uid, gid, permission) {
+		return -1, permissionDenied(filePath)
+	}
+
+	return index, nil
+}
+
+// CheckCreate checks filePath does not exist yet and the user can create it in the deepest of its folders
+// that already exists, the folders created on the way belong to the user and are not checked
+func (sb *SuperBlock) CheckCreate(path string, filePath []string, uid, gid int32) error {
+	index, found, err := sb.ResolvePath(path, filePath, uid, gid)
+	if err != nil {
+		return err
+	}
+
+	if found == len(filePath) {
+		return fmt.Errorf("/%s already exists", strings.Join(filePath, "/"))
+	}
+
+	inode := &Inode{}
+	if err := inode.ReadInode(path, int64(sb.SInodeStart+index*sb.SInodeSize)); err != nil {
+		return err
+	}
+
+	if inode.IType == '0' && !inode.HasPermission(uid, gid, PermissionWrite) {
+		return permissionDenied(filePath[:found])
+	}
+
+	return nil
+}
+
+func permissionDenied(filePath []string) error {
+	return fmt.Errorf("permission denied: /%s", strings.Join(filePath, "/"))
+}
+
+// GetFolderEntries returns the entries of a folder inode, without "." and ".."
+func (sb *SuperBlock) GetFolderEntries(path string, inode *Inode) []FolderContent {
+	var entries []FolderContent
+
+	for _, blockIndex := range sb.getDataBlocks(path, inode) {
+		block := &FolderBlock{}
+		if err := block.ReadFolderBlock(path, int64(sb.SBlockStart+blockIndex*sb.SBlockSize)); err != nil {
+			continue
+		}
+
+		for _, content := range block.BContent[2:] {
+			if content.BInode != -1 {
+				entries = append(entries, content)
+			}
+		}
+	}
+
+	return entries
+}
+
+// getDataBlocks returns the folder or file blocks of an inode in order, following the pointer blocks
+func (sb *SuperBlock) getDataBlocks(path string, inode *Inode) []int32 {
+	var blocks []int32
+
+	for _, blockIndex := range inode.IBlock[:12] {
+		if blockIndex != -1 {
+			blocks = append(blocks, blockIndex)
+		}
+	}
+
+	for i, blockIndex := range inode.IBlock[12:] {
+		if blockIndex != -1 {
+			blocks = append(blocks, sb.getPointerDataBlocks(path, blockIndex, int32(i))...)
+		}
+	}
+
+	return blocks
+}
+
+// getPointerDataBlocks returns the data blocks reachable from a pointer block
+func (sb *SuperBlock) getPointerDataBlocks(path string, blockIndex, level int32) []int32 {
+	block := &PointerBlock{}
+	if err := block.ReadPointerBlock(path, int64(sb.SBlockStart+blockIndex*sb.SBlockSize)); err != nil {
+		return nil
+	}
+
+	var blocks []int32
+	for _, pointer := range block.PPointers {
+		if pointer == -1 {
+			continue
+		}
+
+		if level == 0 {
+			blocks = append(blocks, pointer)
+		} else {
+			blocks = append(blocks, sb.getPointerDataBlocks(path, pointer, level-1)...)
+		}
+	}
+
+	return blocks
+}
+
+// getAllBlocks returns every block used by an inode, the pointer blocks included
+func (sb *SuperBlock) getAllBlocks(path string, inode *Inode) []int32 {
+	var blocks []int32
+
+	for _, blockIndex := range inode.IBlock[:12] {
+		if blockIndex != -1 {
+			blocks = append(blocks, blockIndex)
+		}
+	}
+
+	for i, blockIndex := range inode.IBlock[12:] {
+		if blockIndex != -1 {
+			blocks = append(blocks, sb.getPointerTreeBlocks(path, blockIndex, int32(i))...)
+		}
+	}
+
+	return blocks
+}
+
+// getPointerTreeBlocks returns a pointer block and every block below it
+func (sb *SuperBlock) getPointerTreeBlocks(path string, blockIndex, level int32) []int32 {
+	blocks := []int32{blockIndex}
+
+	block := &PointerBlock{}
+	if err := block.ReadPointerBlock(path, int64(sb.SBlockStart+blockIndex*sb.SBlockSize)); err != nil {
+		return blocks
+	}
+
+	for _, pointer := range block.PPointers {
+		if pointer == -1 {
+			continue
+		}
+
+		if level == 0 {
+			blocks = append(blocks, pointer)
+		} else {
+			blocks = append(blocks, sb.getPointerTreeBlocks(path, pointer, level-1)...)
+		}
+	}
+
+	return blocks
+}
+
+// FindInodeWithoutPermission returns the path of the first inode of the tree rooted at index
+// the user lacks the permission on, or "" if there is none
+func (sb *SuperBlock) FindInodeWithoutPermission(path string, index int32, name string, uid, gid int32, permission byte) (string, error) {
+	inode := &Inode{}
+	if err := inode.ReadInode(path, int64(sb.SInodeStart+index*sb.SInodeSize)); err != nil {
+		return "", err
+	}
+
+	if !inode.HasPermission(uid, gid, permission) {
+		return name, nil
+	}
+
+	if inode.IType != '0' {
+		return "", nil
+	}
+
+	for _, entry := range sb.GetFolderEntries(path, inode) {
+		entryName := strings.TrimRight(string(entry.BName[:]), "\x00")
+		denied, err := sb.FindInodeWithoutPermission(path, entry.BInode, name+"/"+entryName, uid, gid, permission)
+		if err != nil || denied != "" {
+			return denied, err
+		}
+	}
+
+	return "", nil
+}
+
+// RemoveInode removes the entry name from the folder parentIndex and frees its inodes and blocks
+func (sb *SuperBlock) RemoveInode(path string, parentIndex int32, name string) error {
+	index, err := sb.UnlinkEntry(path, parentIndex, name)
+	if err != nil {
+		return err
+	}
+
+	return sb.freeInode(path, index)
+}
+
+// UnlinkEntry removes the entry name from the folder parentIndex and returns the inode it pointed to
+func (sb *SuperBlock) UnlinkEntry(path string, parentIndex int32, name string) (int32, error) {
+	parent := &Inode{}
+	parentPath := int64(sb.SInodeStart + parentIndex*sb.SInodeSize)
+	if err := parent.ReadInode(path, parentPath); err != nil {
+		return -1, err
+	}
+
+	for _, blockIndex := range sb.getDataBlocks(path, parent) {
+		block := &FolderBlock{}
+		blockPath := int64(sb.SBlockStart + blockIndex*sb.SBlockSize)
+		if err := block.ReadFolderBlock(path, blockPath); err != nil {
+			return -1, err
+		}
+
+		for i := 2; i < len(block.BContent); i++ {
+			entry := block.BContent[i]
+			if entry.BInode == -1 || strings.TrimRight(string(entry.BName[:]), "\x00") != name {
+				continue
+			}
+
+			block.BContent[i] = FolderContent{BName: [12]byte{'-'}, BInode: -1}
+			if err := block.WriteFolderBlock(path, blockPath, blockPath+int64(sb.SBlockSize)); err != nil {
+				return -1, err
+			}
+
+			parent.IMTime = float32(time.Now().Unix())
+			return entry.BInode, parent.WriteInode(path, parentPath, parentPath+int64(sb.SInodeSize))
+		}
+	}
+
+	return -1, fmt.Errorf("%s not found", name)
+}
+
+// MoveEntry links the inode of name in the folder destIndex and then unlinks it from the folder parentIndex
+func (sb *SuperBlock) MoveEntry(path string, parentIndex int32, name string, destIndex int32) error {
+	parent := &Inode{}
+	if err := parent.ReadInode(path, int64(sb.SInodeStart+parentIndex*sb.SInodeSize)); err != nil {
+		return err
+	}
+
+	index := sb.findInodeInBlock(path, name, parent)
+	if index == -1 {
+		return fmt.Errorf("%s not found", name)
+	}
+
+	dest := &Inode{}
+	if err := dest.ReadInode(path, int64(sb.SInodeStart+destIndex*sb.SInodeSize)); err != nil {
+		return err
+	}
+
+	if err := sb.AddEntry(path, name, dest, destIndex, index); err != nil {
+		return err
+	}
+
+	if _, err := sb.UnlinkEntry(path, parentIndex, name); err != nil {
+		return err
+	}
+
+	moved := &Inode{}
+	if err := moved.ReadInode(path, int64(sb.SInodeStart+index*sb.SInodeSize)); err != nil {
+		return err
+	}
+
+	if moved.IType != '0' {
+		return nil
+	}
+
+	for _, blockIndex := range sb.getDataBlocks(path, moved) {
+		block := &FolderBlock{}
+		blockPath := int64(sb.SBlockStart + blockIndex*sb.SBlockSize)
+		if err := block.ReadFolderBlock(path, blockPath); err != nil {
+			return err
+		}
+
+		block.BContent[1].BInode = destIndex
+		if err := block.WriteFolderBlock(path, blockPath, blockPath+int64(sb.SBlockSize)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// folderParent returns the ".." of the folder inode, taken from its first block. A folder without
+// blocks is treated as its own parent
+func (sb *SuperBlock) folderParent(path string, inode *Inode, index int32) int32 {
+	if inode.IBlock[0] == -1 {
+		return index
+	}
+
+	block := &FolderBlock{}
+	if err := block.ReadFolderBlock(path, int64(sb.SBlockStart+inode.IBlock[0]*sb.SBlockSize)); err != nil {
+		return index
+	}
+
+	return block.BContent[1].BInode
+}
+
+// freeInode releases an inode, its blocks and, for folders, all of its content
+func (sb *SuperBlock) freeInode(path string, index int32) error {
+	inode := &Inode{}
+	if err := inode.ReadInode(path, int64(sb.SInodeStart+index*sb.SInodeSize)); err != nil {
+		return err
+	}
+
+	if inode.IType == '0' {
+		for _, entry := range sb.GetFolderEntries(path, inode) {
+			if err := sb.freeInode(path, entry.BInode); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, blockIndex := range sb.getAllBlocks(path, inode) {
+		if err := sb.FreeBitmapBlock(path, blockIndex); err != nil {
+			return err
+		}
+	}
+
+	return sb.FreeBitmapInode(path, index)
+}
+
+// ValidateName checks that a name fits in the BName of a FolderContent
+func ValidateName(name string) error {
+	if name == "" || strings.Contains(name, "/") {
+		return fmt.Errorf("invalid name: %s", name)
+	}
+
+	if len(name) > len(FolderContent{}.BName) {
+		return fmt.Errorf("name %s is longer than %d characters", name, len(FolderContent{}.BName))
+	}
+
+	return nil
+}
+
+// RenameEntry changes the name of an entry of the folder parentIndex
+func (sb *SuperBlock) RenameEntry(path string, parentIndex int32, name, newName string) error {
+	if err := ValidateName(newName); err != nil {
+		return err
+	}
+
+	parent := &Inode{}
+	parentPath := int64(sb.SInodeStart + parentIndex*sb.SInodeSize)
+	if err := parent.ReadInode(path, parentPath); err != nil {
+		return err
+	}
+
+	for _, entry := range sb.GetFolderEntries(path, parent) {
+		if strings.TrimRight(string(entry.BName[:]), "\x00") == newName {
+			return fmt.Errorf("%s already exists", newName)
+		}
+	}
+
+	for _, blockIndex := range sb.getDataBlocks(path, parent) {
+		block := &FolderBlock{}
+		blockPath := int64(sb.SBlockStart + blockIndex*sb.SBlockSize)
+		if err := block.ReadFolderBlock(path, blockPath); err != nil {
+			return err
+		}
+
+		for i := 2; i < len(block.BContent); i++ {
+			entry := &block.BContent[i]
+			if entry.BInode == -1 || strings.TrimRight(string(entry.BName[:]), "\x00") != name {
+				continue
+			}
+
+			entry.BName = [12]byte{}
+			copy(entry.BName[:], newName)
+			if err := block.WriteFolderBlock(path, blockPath, blockPath+int64(sb.SBlockSize)); err != nil {
+				return err
+			}
+
+			parent.IMTime = float32(time.Now().Unix())
+			return parent.WriteInode(path, parentPath, parentPath+int64(sb.SInodeSize))
+		}
+	}
+
+	return fmt.Errorf("%s not found", name)
+}
+
+// CopyInode clones the inode srcIndex, and everything under it, into destPath owned by the user. Entries
+// the user cannot read are not copied and their paths are added to skipped
+func (sb *SuperBlock) CopyInode(path string, srcIndex int32, srcPath string, destPath []string, uid, gid int32, skipped *[]string) error {
+	source := &Inode{}
+	if err := source.ReadInode(path, int64(sb.SInodeStart+srcIndex*sb.SInodeSize)); err != nil {
+		return err
+	}
+
+	if !source.HasPermission(uid, gid, PermissionRead) {
+		*skipped = append(*skipped, srcPath)
+		return nil
+	}
+
+	isFile := source.IType == '1'
+	if err := sb.CreateNewInode(path, destPath, 0, isFile, false, uid, gid, DefaultUmask); err != nil {
+		return err
+	}
+
+	newIndex := sb.GetInodeIndex(path, destPath)
+	if newIndex == -1 {
+		return fmt.Errorf("failed to create %s", "/"+strings.Join(destPath, "/"))
+	}
+
+	if isFile {
+		if _, err := sb.WriteFile(path, 0, destPath, sb.getFileContent(path, source)); err != nil {
+			return err
+		}
+	} else {
+		for _, entry := range sb.GetFolderEntries(path, source) {
+			name := strings.TrimRight(string(entry.BName[:]), "\x00")
+			childPath := append(append([]string{}, destPath...), name)
+			if err := sb.CopyInode(path, entry.BInode, srcPath+"/"+name, childPath, uid, gid, skipped); err != nil {
+				return err
+			}
+		}
+	}
+
+	copied := &Inode{}
+	copiedPath := int64(sb.SInodeStart + newIndex*sb.SInodeSize)
+	if err := copied.ReadInode(path, copiedPath); err != nil {
+		return err
+	}
+
+	copied.IPerm = source.IPerm
+
+	return copied.WriteInode(path, copiedPath, copiedPath+int64(sb.SInodeSize))
+}
+
+// UpdateInodes calls update on the inode index and, when recursive, on every inode below it.
+// Inodes are written back only when update returns true
+func (sb *SuperBlock) UpdateInodes(path string, index int32, recursive bool, update func(inode *Inode) bool) error {
+	inode := &Inode{}
+	inodePath := int64(sb.SInodeStart + index*sb.SInodeSize)
+	if err := inode.ReadInode(path, inodePath); err != nil {
+		return err
+	}
+
+	if update(inode) {
+		if err := inode.WriteInode(path, inodePath, inodePath+int64(sb.SInodeSize)); err != nil {
+			return err
+		}
+	}
+
+	if !recursive || inode.IType != '0' {
+		return nil
+	}
+
+	for _, entry := range sb.GetFolderEntries(path, inode) {
+		if err := sb.UpdateInodes(path, entry.BInode, recursive, update); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
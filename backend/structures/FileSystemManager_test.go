@@ -0,0 +1,88 @@
+package structures
+
+import (
+	"testing"
+)
+
+func TestCreatePath(t *testing.T) {
+	tests := []struct {
+		name       string
+		existing   []string
+		freeBlocks int
+		isFile     bool
+		wantErr    bool
+	}{
+		{name: "file in a folder with room", existing: []string{"a"}, freeBlocks: 0, isFile: true},
+		{name: "folder in a folder with room", existing: []string{"a"}, freeBlocks: 1},
+		{name: "file needs a new folder block", existing: []string{"a", "b"}, freeBlocks: 1, isFile: true},
+		{name: "file without a block for the entry", existing: []string{"a", "b"}, freeBlocks: 0, isFile: true, wantErr: true},
+		{name: "folder takes the last block", existing: []string{"a", "b"}, freeBlocks: 1, wantErr: true},
+		{name: "folder without blocks", existing: []string{"a"}, freeBlocks: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sb, path := newTestImage(t, 4)
+			if err := sb.createRootInodeAndBlock(path); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, name := range tt.existing {
+				if err := sb.CreateNewInode(path, []string{name}, 0, true, false, 1, 1, DefaultUmask); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Only the first freeBlocks blocks after the root block are left free
+			bitmap := []byte("XXXXXXXXXXXX")
+			for i := 1; i <= tt.freeBlocks; i++ {
+				bitmap[i] = 'O'
+			}
+			sb.SFreeBlockCount = setBitmap(t, sb, path, sb.SBMBlockStart, string(bitmap), 'O')
+			sb.SFirstBlo = -1
+			if tt.freeBlocks > 0 {
+				sb.SFirstBlo = 1
+			}
+
+			before := *sb
+			usedBefore, err := sb.GetUsedInodes(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = sb.CreateNewInode(path, []string{"c"}, 0, tt.isFile, false, 1, 1, DefaultUmask)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateNewInode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			used, err := sb.GetUsedInodes(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			index := sb.GetInodeIndex(path, []string{"c"})
+
+			if tt.wantErr {
+				if index != -1 {
+					t.Errorf("c is linked to inode %d", index)
+				}
+				if len(used) != len(usedBefore) || sb.SFreeInodeCount != before.SFreeInodeCount || sb.SFreeBlockCount != before.SFreeBlockCount {
+					t.Errorf("free inodes %d, blocks %d, used %v, want %d, %d, %v",
+						sb.SFreeInodeCount, sb.SFreeBlockCount, used, before.SFreeInodeCount, before.SFreeBlockCount, usedBefore)
+				}
+				return
+			}
+
+			if index == -1 || index != used[len(used)-1] {
+				t.Fatalf("c is linked to inode %d, used inodes %v", index, used)
+			}
+
+			child := &Inode{}
+			if err := child.ReadInode(path, int64(sb.SInodeStart+index*sb.SInodeSize)); err != nil {
+				t.Fatal(err)
+			}
+			if !tt.isFile && sb.folderParent(path, child, index) != 0 {
+				t.Errorf(".. of c = %d, want 0", sb.folderParent(path, child, index))
+			}
+		})
+	}
+}
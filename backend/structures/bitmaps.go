@@ -2,6 +2,7 @@ package structures
 
 import (
 	"encoding/binary"
+	"fmt"
 	"os"
 )
 
@@ -47,12 +48,164 @@ func (sb *SuperBlock) CreateBitMaps(path string) error {
 	return nil
 }
 
-func (sb *SuperBlock) UpdateBitmapInode(path string) error {
-	file, err := os.OpenFile(path, os.O_RDWR, 0644)
+// AllocateInode takes the free inode SFirstIno points at and moves SFirstIno to the next free one
+func (sb *SuperBlock) AllocateInode(path string) (int32, error) {
+	index := sb.SFirstIno
+	if sb.SFreeInodeCount == 0 || index < 0 || index >= sb.InodesTotal() {
+		return -1, fmt.Errorf("no free inodes")
+	}
+
+	if err := writeBitmapByte(path, int64(sb.SBMInodeStart+index), '1'); err != nil {
+		return -1, err
+	}
+
+	sb.SInodesCount++
+	sb.SFreeInodeCount--
+
+	next, err := nextFreeSlot(path, sb.SBMInodeStart, sb.InodesTotal(), index, '0')
+	if err != nil {
+		return -1, err
+	}
+	sb.SFirstIno = next
+
+	return index, nil
+}
+
+// AllocateBlock takes the free block SFirstBlo points at and moves SFirstBlo to the next free one
+func (sb *SuperBlock) AllocateBlock(path string) (int32, error) {
+	index := sb.SFirstBlo
+	if sb.SFreeBlockCount == 0 || index < 0 || index >= sb.BlocksTotal() {
+		return -1, fmt.Errorf("no free blocks")
+	}
+
+	if err := writeBitmapByte(path, int64(sb.SBMBlockStart+index), 'X'); err != nil {
+		return -1, err
+	}
+
+	sb.SBlocksCount++
+	sb.SFreeBlockCount--
+
+	next, err := nextFreeSlot(path, sb.SBMBlockStart, sb.BlocksTotal(), index, 'O')
+	if err != nil {
+		return -1, err
+	}
+	sb.SFirstBlo = next
+
+	return index, nil
+}
+
+// repairFirstFree points SFirstIno and SFirstBlo at a free slot again when they are out of range or
+// taken, superblocks written before they were indexes keep byte offsets in them
+func (sb *SuperBlock) repairFirstFree(path string) error {
+	ino, err := firstFreeSlot(path, sb.SBMInodeStart, sb.InodesTotal(), sb.SFirstIno, '0')
+	if err != nil {
+		return err
+	}
+
+	blo, err := firstFreeSlot(path, sb.SBMBlockStart, sb.BlocksTotal(), sb.SFirstBlo, 'O')
 	if err != nil {
 		return err
 	}
 
+	sb.SFirstIno, sb.SFirstBlo = ino, blo
+	return nil
+}
+
+// firstFreeSlot returns index if it is a free slot of the bitmap, otherwise the first free slot from 0
+func firstFreeSlot(path string, bitmapStart, total, index int32, free byte) (int32, error) {
+	if total <= 0 {
+		return -1, nil
+	}
+
+	if index >= 0 && index < total {
+		bitmap, err := readBitmap(path, bitmapStart+index, 1)
+		if err != nil {
+			return -1, err
+		}
+		if bitmap[0] == free {
+			return index, nil
+		}
+	}
+
+	return nextFreeSlot(path, bitmapStart, total, total-1, free)
+}
+
+// ReserveBlockRun points SFirstBlo at the smallest run of free blocks that fits count blocks,
+// so the next allocations are contiguous. SFirstBlo is left untouched if no run is big enough
+func (sb *SuperBlock) ReserveBlockRun(path string, count int32) error {
+	if count <= 1 || sb.SFreeBlockCount < count {
+		return nil
+	}
+
+	bitmap, err := readBitmap(path, sb.SBMBlockStart, sb.BlocksTotal())
+	if err != nil {
+		return err
+	}
+
+	best, bestSize := int32(-1), int32(0)
+	for i := int32(0); i < int32(len(bitmap)); {
+		if bitmap[i] != 'O' {
+			i++
+			continue
+		}
+
+		start := i
+		for i < int32(len(bitmap)) && bitmap[i] == 'O' {
+			i++
+		}
+
+		if size := i - start; size >= count && (best == -1 || size < bestSize) {
+			best, bestSize = start, size
+		}
+	}
+
+	if best != -1 {
+		sb.SFirstBlo = best
+	}
+
+	return nil
+}
+
+// GetUsedInodes returns the indexes marked as used in the inode bitmap
+func (sb *SuperBlock) GetUsedInodes(path string) ([]int32, error) {
+	bitmap, err := readBitmap(path, sb.SBMInodeStart, sb.InodesTotal())
+	if err != nil {
+		return nil, err
+	}
+
+	var used []int32
+	for i, value := range bitmap {
+		if value == '1' {
+			used = append(used, int32(i))
+		}
+	}
+
+	return used, nil
+}
+
+// nextFreeSlot returns the first free slot after index, wrapping around, or -1 if the bitmap is full
+func nextFreeSlot(path string, bitmapStart, total, index int32, free byte) (int32, error) {
+	bitmap, err := readBitmap(path, bitmapStart, total)
+	if err != nil {
+		return -1, err
+	}
+
+	for i := int32(1); i <= total; i++ {
+		slot := (index + i) % total
+		if bitmap[slot] == free {
+			return slot, nil
+		}
+	}
+
+	return -1, nil
+}
+
+func readBitmap(path string, bitmapStart, total int32) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
 	defer func(file *os.File) {
 		err := file.Close()
 		if err != nil {
@@ -60,22 +213,69 @@ func (sb *SuperBlock) UpdateBitmapInode(path string) error {
 		}
 	}(file)
 
-	if _, err = file.Seek(int64(sb.SBMInodeStart)+int64(sb.SInodesCount), 0); err != nil {
+	bitmap := make([]byte, total)
+	if _, err := file.ReadAt(bitmap, int64(bitmapStart)); err != nil {
+		return nil, err
+	}
+
+	return bitmap, nil
+}
+
+// FreeBitmapInode marks an inode as free again
+func (sb *SuperBlock) FreeBitmapInode(path string, index int32) error {
+	if index < 0 || index >= sb.InodesTotal() {
+		return fmt.Errorf("invalid inode index: %d", index)
+	}
+
+	bitmap, err := readBitmap(path, sb.SBMInodeStart+index, 1)
+	if err != nil {
 		return err
 	}
+	if bitmap[0] == '0' {
+		return nil
+	}
 
-	if _, err := file.Write([]byte{'1'}); err != nil {
+	if err := writeBitmapByte(path, int64(sb.SBMInodeStart+index), '0'); err != nil {
 		return err
 	}
 
-	sb.SInodesCount++
-	sb.SFreeInodeCount--
-	sb.SFirstIno += sb.SInodeSize
+	sb.SInodesCount--
+	sb.SFreeInodeCount++
+	if sb.SFirstIno == -1 || index < sb.SFirstIno {
+		sb.SFirstIno = index
+	}
+
+	return nil
+}
+
+// FreeBitmapBlock marks a block as free again
+func (sb *SuperBlock) FreeBitmapBlock(path string, index int32) error {
+	if index < 0 || index >= sb.BlocksTotal() {
+		return fmt.Errorf("invalid block index: %d", index)
+	}
+
+	bitmap, err := readBitmap(path, sb.SBMBlockStart+index, 1)
+	if err != nil {
+		return err
+	}
+	if bitmap[0] == 'O' {
+		return nil
+	}
+
+	if err := writeBitmapByte(path, int64(sb.SBMBlockStart+index), 'O'); err != nil {
+		return err
+	}
+
+	sb.SBlocksCount--
+	sb.SFreeBlockCount++
+	if sb.SFirstBlo == -1 || index < sb.SFirstBlo {
+		sb.SFirstBlo = index
+	}
 
 	return nil
 }
 
-func (sb *SuperBlock) UpdateBitmapBlock(path string) error {
+func writeBitmapByte(path string, offset int64, value byte) error {
 	file, err := os.OpenFile(path, os.O_RDWR, 0644)
 	if err != nil {
 		return err
@@ -88,17 +288,13 @@ func (sb *SuperBlock) UpdateBitmapBlock(path string) error {
 		}
 	}(file)
 
-	if _, err = file.Seek(int64(sb.SBMBlockStart)+int64(sb.SBlocksCount), 0); err != nil {
+	if _, err = file.Seek(offset, 0); err != nil {
 		return err
 	}
 
-	if _, err := file.Write([]byte{'X'}); err != nil {
+	if _, err := file.Write([]byte{value}); err != nil {
 		return err
 	}
 
-	sb.SBlocksCount++
-	sb.SFreeBlockCount--
-	sb.SFirstBlo += sb.SBlockSize
-
 	return nil
 }
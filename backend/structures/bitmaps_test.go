@@ -0,0 +1,163 @@
+package structures
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestImage formats a temporary image with n inodes and 3n blocks, starting at offset 0
+func newTestImage(t *testing.T, n int32) (*SuperBlock, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "disk.mia")
+	sb := &SuperBlock{}
+	sb.CreateSuperBlock(0, n, 2)
+
+	if err := os.WriteFile(path, make([]byte, sb.BlocksEnd()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sb.CreateBitMaps(path); err != nil {
+		t.Fatal(err)
+	}
+
+	return sb, path
+}
+
+// setBitmap writes bitmap over the inode or block bitmap and updates the free counters
+func setBitmap(t *testing.T, sb *SuperBlock, path string, start int32, bitmap string, free byte) int32 {
+	t.Helper()
+
+	count := int32(0)
+	for i := range bitmap {
+		if err := writeBitmapByte(path, int64(start)+int64(i), bitmap[i]); err != nil {
+			t.Fatal(err)
+		}
+		if bitmap[i] == free {
+			count++
+		}
+	}
+
+	return count
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name     string
+		inode    bool
+		bitmap   string
+		first    int32
+		want     int32
+		wantNext int32
+		wantErr  bool
+	}{
+		{name: "inode on empty bitmap", inode: true, bitmap: "0000", first: 0, want: 0, wantNext: 1},
+		{name: "inode wraps around", inode: true, bitmap: "0001", first: 2, want: 2, wantNext: 0},
+		{name: "last free inode", inode: true, bitmap: "1101", first: 2, want: 2, wantNext: -1},
+		{name: "inode out of range", inode: true, bitmap: "0000", first: 4, wantErr: true},
+		{name: "inode as a byte offset", inode: true, bitmap: "0000", first: 200, wantErr: true},
+		{name: "no free inodes", inode: true, bitmap: "1111", first: -1, wantErr: true},
+		{name: "block on empty bitmap", bitmap: "OOOOOOOOOOOO", first: 0, want: 0, wantNext: 1},
+		{name: "block wraps around", bitmap: "OXXXXXXXXXXO", first: 11, want: 11, wantNext: 0},
+		{name: "last free block", bitmap: "XXXXXOXXXXXX", first: 5, want: 5, wantNext: -1},
+		{name: "block out of range", bitmap: "OOOOOOOOOOOO", first: 12, wantErr: true},
+		{name: "no free blocks", bitmap: "XXXXXXXXXXXX", first: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sb, path := newTestImage(t, 4)
+
+			var got, next int32
+			var err error
+			if tt.inode {
+				sb.SFreeInodeCount = setBitmap(t, sb, path, sb.SBMInodeStart, tt.bitmap, '0')
+				sb.SFirstIno = tt.first
+				got, err = sb.AllocateInode(path)
+				next = sb.SFirstIno
+			} else {
+				sb.SFreeBlockCount = setBitmap(t, sb, path, sb.SBMBlockStart, tt.bitmap, 'O')
+				sb.SFirstBlo = tt.first
+				got, err = sb.AllocateBlock(path)
+				next = sb.SFirstBlo
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (got != tt.want || next != tt.wantNext) {
+				t.Errorf("allocated %d, next %d, want %d, next %d", got, next, tt.want, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestReserveBlockRun(t *testing.T) {
+	tests := []struct {
+		name   string
+		bitmap string
+		first  int32
+		count  int32
+		want   int32
+	}{
+		{name: "single block keeps first free", bitmap: "XOOXOOOXOOOO", first: 1, count: 1, want: 1},
+		{name: "smallest run that fits", bitmap: "XOOXOOOXOOOO", first: 1, count: 2, want: 1},
+		{name: "skips runs too small", bitmap: "XOOXOOOXOOOO", first: 1, count: 3, want: 4},
+		{name: "largest run", bitmap: "XOOXOOOXOOOO", first: 1, count: 4, want: 8},
+		{name: "no run big enough", bitmap: "XOOXOOOXOOOO", first: 1, count: 5, want: 1},
+		{name: "not enough free blocks", bitmap: "XXXXXXXXXXOO", first: 10, count: 3, want: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sb, path := newTestImage(t, 4)
+			sb.SFreeBlockCount = setBitmap(t, sb, path, sb.SBMBlockStart, tt.bitmap, 'O')
+			sb.SFirstBlo = tt.first
+
+			if err := sb.ReserveBlockRun(path, tt.count); err != nil {
+				t.Fatal(err)
+			}
+			if sb.SFirstBlo != tt.want {
+				t.Errorf("SFirstBlo = %d, want %d", sb.SFirstBlo, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepairFirstFree(t *testing.T) {
+	tests := []struct {
+		name      string
+		inodes    string
+		firstIno  int32
+		wantIno   int32
+		blocks    string
+		firstBlo  int32
+		wantBlo   int32
+		useOffset bool
+	}{
+		{name: "valid indexes are kept", inodes: "1100", firstIno: 2, wantIno: 2, blocks: "XOOOOOOOOOOO", firstBlo: 5, wantBlo: 5},
+		{name: "taken slots move to the first free", inodes: "1010", firstIno: 0, wantIno: 1, blocks: "XXXOXXXXXXXX", firstBlo: 1, wantBlo: 3},
+		{name: "byte offsets of old superblocks", inodes: "1100", wantIno: 2, blocks: "XXOOOOOOOOOO", wantBlo: 2, useOffset: true},
+		{name: "full bitmaps", inodes: "1111", firstIno: 3, wantIno: -1, blocks: "XXXXXXXXXXXX", firstBlo: 7, wantBlo: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sb, path := newTestImage(t, 4)
+			sb.SFreeInodeCount = setBitmap(t, sb, path, sb.SBMInodeStart, tt.inodes, '0')
+			sb.SFreeBlockCount = setBitmap(t, sb, path, sb.SBMBlockStart, tt.blocks, 'O')
+			sb.SFirstIno, sb.SFirstBlo = tt.firstIno, tt.firstBlo
+			if tt.useOffset {
+				sb.SFirstIno, sb.SFirstBlo = sb.SInodeStart, sb.SBlockStart
+			}
+
+			if err := sb.repairFirstFree(path); err != nil {
+				t.Fatal(err)
+			}
+			if sb.SFirstIno != tt.wantIno || sb.SFirstBlo != tt.wantBlo {
+				t.Errorf("first free = %d, %d, want %d, %d", sb.SFirstIno, sb.SFirstBlo, tt.wantIno, tt.wantBlo)
+			}
+		})
+	}
+}
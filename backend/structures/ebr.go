@@ -31,9 +31,26 @@ func (e *EBR) SetEBR(fit string, start int32, size int32, next int32, name strin
 	e.PartStart = start
 	e.PartSize = size
 	e.PartNext = next
+	e.PartName = [16]byte{}
 	copy(e.PartName[:], name)
 }
 
+func (e *EBR) IsEmpty() bool {
+	return e.PartStart == -1 && e.PartSize == -1
+}
+
+func (e *EBR) MountPartition() {
+	e.PartMount = '1'
+}
+
+func (e *EBR) UnmountPartition() {
+	e.PartMount = '0'
+}
+
+func (e *EBR) IsMounted() bool {
+	return e.PartMount == '1'
+}
+
 func (e *EBR) WriteEBR(path string, offset int64, maxSize int64) error {
 	if err := utils.WriteToFile(path, offset, maxSize, e); err != nil {
 		return err
@@ -48,6 +65,54 @@ func (e *EBR) ReadEBR(path string, offset int64) error {
 	return nil
 }
 
+// EBRNode is an EBR together with the offset where it is stored on disk
+type EBRNode struct {
+	EBR    EBR
+	Offset int64
+}
+
+// ReadEBRChain reads every EBR linked from the start of the extended partition
+func ReadEBRChain(path string, extended *Partition) ([]EBRNode, error) {
+	var chain []EBRNode
+
+	offset := int64(extended.PartStart)
+	for offset != -1 {
+		ebr := EBR{}
+		if err := ebr.ReadEBR(path, offset); err != nil {
+			return nil, err
+		}
+
+		chain = append(chain, EBRNode{EBR: ebr, Offset: offset})
+
+		next := int64(ebr.PartNext)
+		if next != -1 && next <= offset {
+			return nil, fmt.Errorf("corrupted EBR chain at offset %d", offset)
+		}
+		offset = next
+	}
+
+	return chain, nil
+}
+
+// FindLogicalPartition returns the logical partition with the given name
+func FindLogicalPartition(path string, extended *Partition, name string) (*EBRNode, error) {
+	chain, err := ReadEBRChain(path, extended)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, node := range chain {
+		if node.EBR.IsEmpty() {
+			continue
+		}
+		if strings.TrimRight(string(node.EBR.PartName[:]), "\x00") == name {
+			return &chain[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("logical partition not found: %s", name)
+}
+
 func (e *EBR) Print() {
 	fmt.Println("PartMount: ", string(e.PartMount))
 	fmt.Println("PartFit: ", string(e.PartFit))
@@ -33,8 +33,26 @@ func (f *FileBlock) Print() {
 func (f *FileBlock) GetStringBuilder(nodeName string) string {
 	var sb strings.Builder
 
-	content := strings.ReplaceAll(string(f.BContent[:]), "\x00", "")
-	content2 := strings.ReplaceAll(content, "\n", "<br/>")
+	var content strings.Builder
+	for _, char := range f.BContent {
+		switch {
+		case char == 0:
+		case char == '\n':
+			content.WriteString("<br/>")
+		case char == '<':
+			content.WriteString("&lt;")
+		case char == '>':
+			content.WriteString("&gt;")
+		case char == '&':
+			content.WriteString("&amp;")
+		case char < 32 || char > 126:
+			// Non printable bytes would break the label, usually a sign of a corrupted block
+			content.WriteString(fmt.Sprintf("\\x%02x", char))
+		default:
+			content.WriteByte(char)
+		}
+	}
+	content2 := content.String()
 
 	sb.WriteString(fmt.Sprintf("    %s [label=<\n", nodeName))
 	sb.WriteString(fmt.Sprintf("    <TABLE BORDER=\"0\" CELLBORDER=\"1\" CELLSPACING=\"0\">\n"))
@@ -63,6 +63,15 @@ func (f *FolderBlock) GetStringBuilder(nodeName string) string {
 	if f.BContent[3].BInode != -1 {
 		sb.WriteString(fmt.Sprintf("%s -> Inodo_%d\n", nodeName, f.BContent[3].BInode))
 	}
+	sb.WriteString(f.GetNodeStringBuilder(nodeName))
+
+	return sb.String()
+}
+
+// GetNodeStringBuilder returns only the node of the folder block, without its edges
+func (f *FolderBlock) GetNodeStringBuilder(nodeName string) string {
+	var sb strings.Builder
+
 	sb.WriteString(fmt.Sprintf("    %s [label=<\n", nodeName))
 	sb.WriteString(fmt.Sprintf("    <TABLE BORDER=\"0\" CELLBORDER=\"1\" CELLSPACING=\"0\">\n"))
 
@@ -19,6 +19,14 @@ type Inode struct {
 	IPerm  [3]byte
 }
 
+const (
+	PermissionRead    byte = 4
+	PermissionWrite   byte = 2
+	PermissionExecute byte = 1
+	// RootUserID is the id of the root user, who bypasses every permission
+	RootUserID int32 = 1
+)
+
 func (i *Inode) DefaultValue(blockCount int32) {
 	i.IuId = 1
 	i.IGid = 1
@@ -36,6 +44,83 @@ func (i *Inode) DefaultValue(blockCount int32) {
 	}
 }
 
+// GetPermissionString returns the permissions in the ls format, e.g. drwxrw-r--
+func (i *Inode) GetPermissionString() string {
+	var sb strings.Builder
+
+	if i.IType == '0' {
+		sb.WriteByte('d')
+	} else {
+		sb.WriteByte('-')
+	}
+
+	for _, digit := range i.IPerm {
+		value := digit - '0'
+		for j, letter := range "rwx" {
+			if value&(4>>j) != 0 {
+				sb.WriteRune(letter)
+			} else {
+				sb.WriteByte('-')
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+// DefaultUmask gives new folders 775 and new files 664
+var DefaultUmask = [3]byte{'0', '0', '2'}
+
+// NewPermission returns the IPerm of a new folder or file, they start from 777 and 666
+// and the bits set in umask are removed
+func NewPermission(isFile bool, umask [3]byte) [3]byte {
+	base := byte(7)
+	if isFile {
+		base = 6
+	}
+
+	var perm [3]byte
+	for j := range perm {
+		perm[j] = '0' + base&^(umask[j]-'0')
+	}
+
+	return perm
+}
+
+// ParsePermission validates an octal ugo string such as 755 and returns it as IPerm bytes
+func ParsePermission(ugo string) ([3]byte, error) {
+	var perm [3]byte
+	if len(ugo) != len(perm) {
+		return perm, fmt.Errorf("invalid permission %s, it must have 3 digits", ugo)
+	}
+
+	for j := 0; j < len(perm); j++ {
+		if ugo[j] < '0' || ugo[j] > '7' {
+			return perm, fmt.Errorf("invalid permission %s, each digit must be between 0 and 7", ugo)
+		}
+		perm[j] = ugo[j]
+	}
+
+	return perm, nil
+}
+
+// HasPermission checks the owner, group or other digit of IPerm that applies to the user,
+// every bit of permission must be set
+func (i *Inode) HasPermission(uid, gid int32, permission byte) bool {
+	if uid == RootUserID {
+		return true
+	}
+
+	digit := i.IPerm[2]
+	if i.IuId == uid {
+		digit = i.IPerm[0]
+	} else if i.IGid == gid {
+		digit = i.IPerm[1]
+	}
+
+	return (digit-'0')&permission == permission
+}
+
 func (i *Inode) WriteInode(path string, offset int64, maxSize int64) error {
 	if err := utils.WriteToFile(path, offset, maxSize, i); err != nil {
 		return err
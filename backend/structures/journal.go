@@ -0,0 +1,289 @@
+package structures
+
+import (
+	"backend/utils"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Information struct {
+	IOperation [10]byte
+	IPath      [32]byte
+	IContent   [64]byte
+	IDate      float32
+	// Total size of the Information is 110 bytes
+}
+
+type Journal struct {
+	JCount   int32
+	JContent Information
+	// Total size of the Journal is 114 bytes
+}
+
+// Operations whose path or content does not fit in one record continue in the
+// following records with this operation name and the same JCount
+const journalContinuation = "+"
+
+func (j *Journal) WriteJournal(path string, offset int64, maxSize int64) error {
+	if err := utils.WriteToFile(path, offset, maxSize, j); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (j *Journal) ReadJournal(path string, offset int64) error {
+	if err := utils.ReadFromFile(path, offset, j); err != nil {
+		return err
+	}
+	return nil
+}
+
+// IsEmpty reports whether the record has not been used yet
+func (j *Journal) IsEmpty() bool {
+	return j.JCount == 0
+}
+
+func (j *Journal) Print() {
+	fmt.Printf("JCount: %d\n", j.JCount)
+	fmt.Printf("IOperation: %s\n", string(j.JContent.IOperation[:]))
+	fmt.Printf("IPath: %s\n", string(j.JContent.IPath[:]))
+	fmt.Printf("IContent: %s\n", string(j.JContent.IContent[:]))
+	fmt.Printf("IDate: %s\n", time.Unix(int64(j.JContent.IDate), 0))
+}
+
+func (sb *SuperBlock) HasJournal() bool {
+	return sb.SFilesystemType == 3
+}
+
+// JournalStart returns the offset of the first journal record, right after the superblock
+func (sb *SuperBlock) JournalStart() int32 {
+	return sb.SBMInodeStart - sb.InodesTotal()*int32(binary.Size(Journal{}))
+}
+
+func (sb *SuperBlock) CreateJournal(path string) error {
+	if !sb.HasJournal() {
+		return nil
+	}
+
+	return utils.FillWithZeros(path, int64(sb.JournalStart()), int64(sb.SBMInodeStart-sb.JournalStart()))
+}
+
+// AddJournal appends an operation to the journal, it does nothing on ext2
+func (sb *SuperBlock) AddJournal(path string, operation string, filePath string, content string) error {
+	if !sb.HasJournal() {
+		return nil
+	}
+
+	first, count, err := sb.journalRoom(path, filePath, content)
+	if err != nil {
+		return err
+	}
+
+	_, err = sb.writeJournal(path, first, count+1, operation, filePath, content)
+	return err
+}
+
+// CheckJournalSpace fails if an operation with filePath and content would not fit in the journal,
+// commands call it before changing the filesystem so they never leave changes without a record
+func (sb *SuperBlock) CheckJournalSpace(path string, filePath string, content string) error {
+	if !sb.HasJournal() {
+		return nil
+	}
+
+	_, _, err := sb.journalRoom(path, filePath, content)
+	return err
+}
+
+// journalRoom returns the record where an operation with filePath and content would start and the
+// count of the last operation. When the operation does not fit after the last one, the journal is
+// checkpointed first
+func (sb *SuperBlock) journalRoom(path string, filePath string, content string) (int32, int32, error) {
+	first, count, err := sb.journalEnd(path)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	records := journalRecords(filePath, content)
+	if first+records <= sb.InodesTotal() {
+		return first, count, nil
+	}
+
+	return sb.checkpointJournal(path, count, records)
+}
+
+// checkpointJournal clears the journal once its operations are on disk. It starts again with
+// users.txt and the journaled session, so recovery still knows the users and who the operations
+// that follow belong to. Like journalRoom it returns the first empty record and the last count, and
+// fails without clearing anything when records more do not fit after them
+func (sb *SuperBlock) checkpointJournal(path string, count int32, records int32) (int32, int32, error) {
+	entries, err := sb.GetJournalEntries(path)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	checkpoint := []JournalEntry{{Operation: "edit", Path: "/users.txt", Content: sb.GetFile(path, 0, []string{"users.txt"})}}
+	var login, umask *JournalEntry
+	for i := range entries {
+		switch entries[i].Operation {
+		case "login":
+			login, umask = &entries[i], nil
+		case "umask":
+			umask = &entries[i]
+		}
+	}
+	if login != nil {
+		checkpoint = append(checkpoint, *login)
+	}
+	if umask != nil {
+		checkpoint = append(checkpoint, *umask)
+	}
+
+	needed := records
+	for _, entry := range checkpoint {
+		needed += journalRecords(entry.Path, entry.Content)
+	}
+	if needed > sb.InodesTotal() {
+		return 0, 0, fmt.Errorf("operation does not fit in the journal")
+	}
+
+	if err := sb.CreateJournal(path); err != nil {
+		return 0, 0, err
+	}
+
+	first := int32(0)
+	for _, entry := range checkpoint {
+		count++
+		if first, err = sb.writeJournal(path, first, count, entry.Operation, entry.Path, entry.Content); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return first, count, nil
+}
+
+// writeJournal writes an operation from record first on and returns the record that follows it
+func (sb *SuperBlock) writeJournal(path string, first int32, count int32, operation string, filePath string, content string) (int32, error) {
+	records := journalRecords(filePath, content)
+	pathChunks := splitChunks(filePath, len(Information{}.IPath))
+	contentChunks := splitChunks(content, len(Information{}.IContent))
+
+	journalSize := int32(binary.Size(Journal{}))
+
+	date := float32(time.Now().Unix())
+	for i := 0; i < int(records); i++ {
+		journal := &Journal{}
+		journal.JCount = count
+		journal.JContent.IDate = date
+
+		if i == 0 {
+			copy(journal.JContent.IOperation[:], operation)
+		} else {
+			copy(journal.JContent.IOperation[:], journalContinuation)
+		}
+		if i < len(pathChunks) {
+			copy(journal.JContent.IPath[:], pathChunks[i])
+		}
+		if i < len(contentChunks) {
+			copy(journal.JContent.IContent[:], contentChunks[i])
+		}
+
+		offset := sb.JournalStart() + (first+int32(i))*journalSize
+		if err := journal.WriteJournal(path, int64(offset), int64(offset+journalSize)); err != nil {
+			return 0, err
+		}
+	}
+
+	return first + records, nil
+}
+
+// journalEnd returns the first empty record of the journal and the count of the last operation
+func (sb *SuperBlock) journalEnd(path string) (int32, int32, error) {
+	journalSize := int32(binary.Size(Journal{}))
+
+	first := int32(0)
+	count := int32(0)
+	for ; first < sb.InodesTotal(); first++ {
+		journal := &Journal{}
+		if err := journal.ReadJournal(path, int64(sb.JournalStart()+first*journalSize)); err != nil {
+			return 0, 0, err
+		}
+		if journal.IsEmpty() {
+			break
+		}
+		count = journal.JCount
+	}
+
+	return first, count, nil
+}
+
+// journalRecords returns how many records an operation with filePath and content takes
+func journalRecords(filePath string, content string) int32 {
+	records := len(splitChunks(filePath, len(Information{}.IPath)))
+	if chunks := len(splitChunks(content, len(Information{}.IContent))); chunks > records {
+		records = chunks
+	}
+	return int32(records)
+}
+
+func splitChunks(s string, size int) []string {
+	var chunks []string
+	for len(s) > size {
+		chunks = append(chunks, s[:size])
+		s = s[size:]
+	}
+	if s != "" || len(chunks) == 0 {
+		chunks = append(chunks, s)
+	}
+	return chunks
+}
+
+// JournalEntry is an operation read back from the journal, with its continuation records merged
+type JournalEntry struct {
+	Count     int32
+	Operation string
+	Path      string
+	Content   string
+	Date      float32
+}
+
+func (sb *SuperBlock) GetJournalEntries(path string) ([]JournalEntry, error) {
+	if !sb.HasJournal() {
+		return nil, fmt.Errorf("the file system does not have a journal")
+	}
+
+	journalSize := int32(binary.Size(Journal{}))
+	var entries []JournalEntry
+
+	for i := int32(0); i < sb.InodesTotal(); i++ {
+		journal := &Journal{}
+		if err := journal.ReadJournal(path, int64(sb.JournalStart()+i*journalSize)); err != nil {
+			return nil, err
+		}
+		if journal.IsEmpty() {
+			break
+		}
+
+		operation := strings.TrimRight(string(journal.JContent.IOperation[:]), "\x00")
+		filePath := strings.TrimRight(string(journal.JContent.IPath[:]), "\x00")
+		content := strings.TrimRight(string(journal.JContent.IContent[:]), "\x00")
+
+		if operation == journalContinuation && len(entries) > 0 {
+			last := &entries[len(entries)-1]
+			last.Path += filePath
+			last.Content += content
+			continue
+		}
+
+		entries = append(entries, JournalEntry{
+			Count:     journal.JCount,
+			Operation: operation,
+			Path:      filePath,
+			Content:   content,
+			Date:      journal.JContent.IDate,
+		})
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,43 @@
+package structures
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MountedVolume is the common view of a mounted primary or logical partition
+type MountedVolume struct {
+	Id    string
+	Name  string
+	Type  byte
+	Start int32
+	Size  int32
+}
+
+func (v *MountedVolume) SetFromPartition(p *Partition) {
+	v.Id = strings.TrimRight(string(p.PartId[:]), "\x00")
+	v.Name = strings.TrimRight(string(p.PartName[:]), "\x00")
+	v.Type = p.PartType
+	v.Start = p.PartStart
+	v.Size = p.PartSize
+}
+
+func (v *MountedVolume) SetFromEBR(e *EBR, id string) {
+	v.Id = id
+	v.Name = strings.TrimRight(string(e.PartName[:]), "\x00")
+	v.Type = 'L'
+	v.Start = e.PartStart
+	v.Size = e.PartSize
+}
+
+func (v *MountedVolume) CalculateN(filesystemType int32) int32 {
+	return calculateN(v.Size, filesystemType)
+}
+
+func (v *MountedVolume) Print() {
+	fmt.Println("Id: ", v.Id)
+	fmt.Println("Name: ", v.Name)
+	fmt.Println("Type: ", string(v.Type))
+	fmt.Println("Start: ", v.Start)
+	fmt.Println("Size: ", v.Size)
+}
@@ -25,6 +25,7 @@ func (p *Partition) DefaultValue() {
 	p.PartFit = 'W'
 	p.PartStart = -1
 	p.PartSize = -1
+	p.PartName = [16]byte{}
 	copy(p.PartName[:], "$")
 	p.PartCorrelative = -1
 	copy(p.PartId[:], "$$$$")
@@ -58,9 +59,16 @@ func (p *Partition) IsMounted() bool {
 	return p.PartCorrelative != -1
 }
 
-func (p *Partition) CalculateN() int32 {
-	numerator := int(p.PartSize) - binary.Size(SuperBlock{})
+func (p *Partition) CalculateN(filesystemType int32) int32 {
+	return calculateN(p.PartSize, filesystemType)
+}
+
+func calculateN(size int32, filesystemType int32) int32 {
+	numerator := int(size) - binary.Size(SuperBlock{})
 	denominator := 4 + binary.Size(Inode{}) + 3*binary.Size(FileBlock{})
+	if filesystemType == 3 {
+		denominator += binary.Size(Journal{})
+	}
 	return int32(math.Floor(float64(numerator) / float64(denominator)))
 }
 
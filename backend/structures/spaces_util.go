@@ -1,5 +1,10 @@
 package structures
 
+import (
+	"encoding/binary"
+	"sort"
+)
+
 type Space struct {
 	Start int32
 	End   int32
@@ -19,11 +24,18 @@ func getAvailableSpaces(objects []interface{}, start int32, end int32) []Space {
 		var objStart, objEnd, objSize int32
 		switch v := obj.(type) {
 		case Partition:
+			if v.IsEmpty() {
+				continue
+			}
 			objStart = v.PartStart
 			objSize = v.PartSize
 		case EBR:
-			objStart = v.PartStart
-			objSize = v.PartSize
+			if v.IsEmpty() {
+				continue
+			}
+			// A logical partition also occupies the EBR stored right before it
+			objStart = v.PartStart - int32(binary.Size(EBR{}))
+			objSize = v.PartSize + int32(binary.Size(EBR{}))
 		default:
 			continue
 		}
@@ -37,6 +49,10 @@ func getAvailableSpaces(objects []interface{}, start int32, end int32) []Space {
 		}
 	}
 
+	sort.Slice(occupiedSpaces, func(i, j int) bool {
+		return occupiedSpaces[i].Start < occupiedSpaces[j].Start
+	})
+
 	var availableSpaces []Space
 	currentStart := start
 
@@ -97,3 +113,26 @@ func BestFit(objects []interface{}, blockSize int32, start int32, end int32) int
 	}
 	return -1
 }
+
+// FindSpace places a block following the given fit policy (B, F or W)
+func FindSpace(fit byte, objects []interface{}, blockSize int32, start int32, end int32) int32 {
+	switch fit {
+	case 'B', 'b':
+		return BestFit(objects, blockSize, start, end)
+	case 'W', 'w':
+		return WorstFit(objects, blockSize, start, end)
+	default:
+		return FirstFit(objects, blockSize, start, end)
+	}
+}
+
+// FreeSpaceAfter returns how many contiguous free bytes there are starting at position
+func FreeSpaceAfter(objects []interface{}, position int32, start int32, end int32) int32 {
+	spaces := getAvailableSpaces(objects, start, end)
+	for _, space := range spaces {
+		if space.Start == position {
+			return space.End - space.Start + 1
+		}
+	}
+	return 0
+}
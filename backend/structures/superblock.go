@@ -29,9 +29,15 @@ type SuperBlock struct {
 	// Total size of the SuperBlock is 68 bytes
 }
 
-func (sb *SuperBlock) CreateSuperBlock(partitionStart int32, n int32) {
+func (sb *SuperBlock) CreateSuperBlock(partitionStart int32, n int32, filesystemType int32) {
+	//Journal
+	journalSize := int32(0)
+	if filesystemType == 3 {
+		journalSize = int32(binary.Size(Journal{})) * n
+	}
+
 	//Bitmaps
-	bmInodeStart := partitionStart + int32(binary.Size(sb))
+	bmInodeStart := partitionStart + int32(binary.Size(sb)) + journalSize
 	bmBlockStart := bmInodeStart + n
 
 	//Inodes
@@ -40,7 +46,7 @@ func (sb *SuperBlock) CreateSuperBlock(partitionStart int32, n int32) {
 	//Blocks
 	blockStart := inodeStart + (int32(binary.Size(Inode{})) * n)
 
-	sb.SFilesystemType = 2
+	sb.SFilesystemType = filesystemType
 	sb.SInodesCount = 0
 	sb.SBlocksCount = 0
 	sb.SFreeInodeCount = n
@@ -51,14 +57,33 @@ func (sb *SuperBlock) CreateSuperBlock(partitionStart int32, n int32) {
 	sb.SMagic = 0xEF53
 	sb.SInodeSize = int32(binary.Size(Inode{}))
 	sb.SBlockSize = int32(binary.Size(FileBlock{}))
-	sb.SFirstIno = inodeStart
-	sb.SFirstBlo = blockStart
+	sb.SFirstIno = 0
+	sb.SFirstBlo = 0
 	sb.SBMInodeStart = bmInodeStart
 	sb.SBMBlockStart = bmBlockStart
 	sb.SInodeStart = inodeStart
 	sb.SBlockStart = blockStart
 }
 
+func (sb *SuperBlock) IsFormatted() bool {
+	return sb.SMagic == 0xEF53
+}
+
+// InodesTotal returns how many inodes fit in the filesystem, one per byte of the inode bitmap
+func (sb *SuperBlock) InodesTotal() int32 {
+	return sb.SBMBlockStart - sb.SBMInodeStart
+}
+
+// BlocksTotal returns how many blocks fit in the filesystem, one per byte of the block bitmap
+func (sb *SuperBlock) BlocksTotal() int32 {
+	return sb.SInodeStart - sb.SBMBlockStart
+}
+
+// BlocksEnd returns the offset where the block area of the filesystem ends
+func (sb *SuperBlock) BlocksEnd() int32 {
+	return sb.SBlockStart + sb.BlocksTotal()*sb.SBlockSize
+}
+
 func (sb *SuperBlock) WriteSuperBlock(path string, offset int64, maxSize int64) error {
 	if err := utils.WriteToFile(path, offset, maxSize, sb); err != nil {
 		return err
@@ -70,6 +95,10 @@ func (sb *SuperBlock) ReadSuperBlock(path string, offset int64) error {
 	if err := utils.ReadFromFile(path, offset, sb); err != nil {
 		return err
 	}
+
+	if sb.IsFormatted() {
+		return sb.repairFirstFree(path)
+	}
 	return nil
 }
 
@@ -87,33 +116,29 @@ func (sb *SuperBlock) CreateUserFile(path string) error {
 
 func (sb *SuperBlock) createRootInodeAndBlock(path string) error {
 	rootInode := &Inode{}
-	rootInode.DefaultValue(sb.SBlocksCount)
+	rootInode.DefaultValue(sb.SFirstBlo)
 
-	if err := rootInode.WriteInode(path, int64(sb.SFirstIno), int64(sb.SFirstIno+sb.SInodeSize)); err != nil {
+	inodeIndex, err := sb.AllocateInode(path)
+	if err != nil {
 		return err
 	}
 
-	if err := sb.UpdateBitmapInode(path); err != nil {
+	if err := rootInode.WriteInode(path, int64(sb.SInodeStart+inodeIndex*sb.SInodeSize), int64(sb.SInodeStart+(inodeIndex+1)*sb.SInodeSize)); err != nil {
 		return err
 	}
 
 	rootBlock := &FolderBlock{}
 	rootBlock.DefaultValue()
 
-	if err := rootBlock.WriteFolderBlock(path, int64(sb.SFirstBlo), int64(sb.SFirstBlo+sb.SBlockSize)); err != nil {
+	blockIndex, err := sb.AllocateBlock(path)
+	if err != nil {
 		return err
 	}
 
-	if err := sb.UpdateBitmapBlock(path); err != nil {
+	if err := rootBlock.WriteFolderBlock(path, int64(sb.SBlockStart+blockIndex*sb.SBlockSize), int64(sb.SBlockStart+(blockIndex+1)*sb.SBlockSize)); err != nil {
 		return err
 	}
 
-	fmt.Println("rootInode")
-	rootInode.Print()
-
-	fmt.Println("rootBlock")
-	rootBlock.Print()
-
 	return nil
 }
 
@@ -136,33 +161,37 @@ func (sb *SuperBlock) createUsersFile(path string) error {
 		return err
 	}
 
-	rootBlock.BContent[2] = FolderContent{BName: [12]byte{'u', 's', 'e', 'r', 's', '.', 't', 'x', 't'}, BInode: sb.SInodesCount}
+	rootBlock.BContent[2] = FolderContent{BName: [12]byte{'u', 's', 'e', 'r', 's', '.', 't', 'x', 't'}, BInode: sb.SFirstIno}
 
 	if err := rootBlock.WriteFolderBlock(path, int64(sb.SBlockStart+0), int64(sb.SBlockStart+sb.SBlockSize)); err != nil {
 		return err
 	}
 
 	usersInode := &Inode{}
-	usersInode.DefaultValue(sb.SBlocksCount)
+	usersInode.DefaultValue(sb.SFirstBlo)
 	usersInode.ISize = int32(len(usersText))
 	usersInode.IType = '1'
+	// Owned by root, only root may write the users and their passwords
+	usersInode.IPerm = [3]byte{'6', '6', '4'}
 
-	if err := usersInode.WriteInode(path, int64(sb.SFirstIno), int64(sb.SFirstIno+sb.SInodeSize)); err != nil {
+	inodeIndex, err := sb.AllocateInode(path)
+	if err != nil {
 		return err
 	}
 
-	if err := sb.UpdateBitmapInode(path); err != nil {
+	if err := usersInode.WriteInode(path, int64(sb.SInodeStart+inodeIndex*sb.SInodeSize), int64(sb.SInodeStart+(inodeIndex+1)*sb.SInodeSize)); err != nil {
 		return err
 	}
 
 	usersBlock := &FileBlock{}
 	copy(usersBlock.BContent[:], usersText)
 
-	if err := usersBlock.WriteFileBlock(path, int64(sb.SFirstBlo), int64(sb.SFirstBlo+sb.SBlockSize)); err != nil {
+	blockIndex, err := sb.AllocateBlock(path)
+	if err != nil {
 		return err
 	}
 
-	if err := sb.UpdateBitmapBlock(path); err != nil {
+	if err := usersBlock.WriteFileBlock(path, int64(sb.SBlockStart+blockIndex*sb.SBlockSize), int64(sb.SBlockStart+(blockIndex+1)*sb.SBlockSize)); err != nil {
 		return err
 	}
 
@@ -79,6 +79,42 @@ func ReadFromFile(path string, offset int64, data interface{}) error {
 	return nil
 }
 
+func FillWithZeros(path string, offset int64, size int64) error {
+	file, err := os.OpenFile(path, os.O_WRONLY, 0666)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %v", err)
+	}
+
+	defer func(file *os.File) {
+		err := file.Close()
+		if err != nil {
+			panic(err)
+		}
+	}(file)
+
+	if _, err = file.Seek(offset, 0); err != nil {
+		return fmt.Errorf("failed to seek file: %v", err)
+	}
+
+	buffer := make([]byte, 1024*1024)
+
+	for size > 0 {
+		writeSize := int64(len(buffer))
+
+		if size < writeSize {
+			writeSize = size
+		}
+
+		if _, err := file.Write(buffer[:writeSize]); err != nil {
+			return fmt.Errorf("failed to write to file: %v", err)
+		}
+
+		size -= writeSize
+	}
+
+	return nil
+}
+
 func ReadFromBitMap(path string, offset int64, end int64) (string, error) {
 	if end <= offset {
 		return "", fmt.Errorf("end must be greater than offset")